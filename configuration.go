@@ -5,18 +5,45 @@ import "errors"
 import "time"
 import "net/url"
 import "os/user"
+import "crypto/tls"
+import "crypto/x509"
+import "io/ioutil"
+import "net"
+import "net/http"
+import "strings"
 
 const WebHdfsVer string = "/webhdfs/v1"
 
 type Configuration struct {
-	Addr                  string // host:port
-	BasePath              string // initial base path to be appended
-	User                  string // user.name to use to connect
+	Addr                  string       // host:port
+	Addrs                 []string     // additional namenode host:port for HA failover
+	Endpoint              string       // optional, base URL replacing Addr and UseTLS (i.e. a Knox gateway)
+	NoRedirect            bool         // locate datanodes with noredirect=true, for proxies mishandling redirects
+	Flavor                ServerFlavor // WebHDFS or HttpFS, detected by default
+	BasePath              string       // initial base path to be appended
+	User                  string       // user.name to use to connect
+	BasicAuthUser         string       // optional, HTTP Basic credentials sent to the Endpoint or namenode
+	BasicAuthPassword     string
+	ProxyUser             string      // optional, user to impersonate (doas)
+	DelegationToken       string      // delegation token urlString, sent instead of user.name
+	TokenSource           TokenSource // optional, overrides DelegationToken (i.e. TokenManager)
 	ConnectionTimeout     time.Duration
 	DisableKeepAlives     bool
 	DisableCompression    bool
 	ResponseHeaderTimeout time.Duration
 	MaxIdleConnsPerHost   int
+	Authenticator         Authenticator     // optional, i.e. SpnegoAuthenticator or OAuth2Authenticator
+	UseTLS                bool              // connect over HTTPS (swebhdfs)
+	TLSRootCAs            *x509.CertPool    // CAs used to verify servers, defaults to system pool
+	TLSCertificates       []tls.Certificate // client certificates for mutual TLS
+	TLSServerName         string            // overrides the server name verified for the namenode
+	TLSMinVersion         uint16            // i.e. tls.VersionTLS12
+	TLSInsecureSkipVerify bool
+	DataNodeAddrs         map[string]string        // optional, rewrites datanode host:port (or host) from redirects
+	DataNodeResolver      func(addr string) string // optional, rewrites datanode host:port, after DataNodeAddrs
+	RetryPolicy           *RetryPolicy             // optional, retries transient failures (see NewRetryPolicy)
+	Transport             http.RoundTripper        // optional, replaces the built-in transport and its dial/TLS settings
+	Middleware            []Middleware             // optional, wraps the transport of every request, first is outermost
 }
 
 func NewConfiguration() *Configuration {
@@ -29,17 +56,28 @@ func NewConfiguration() *Configuration {
 }
 
 func (conf *Configuration) GetNameNodeUrl() (*url.URL, error) {
-	if &conf.Addr == nil {
-		return nil, errors.New("Configuration namenode address not set.")
+	base, err := conf.baseUrl()
+	if err != nil {
+		return nil, err
 	}
+	var urlStr string = base.String() + conf.BasePath
 
-	var urlStr string = fmt.Sprintf("http://%s%s%s", conf.Addr, WebHdfsVer, conf.BasePath)
-
-	if &conf.User == nil || len(conf.User) == 0 {
-		u, _ := user.Current()
-		conf.User = u.Username
+	var params []string
+	if token := conf.delegationToken(); token != "" {
+		params = append(params, "delegation="+url.QueryEscape(token))
+	} else if !conf.authenticatorIdentifiesUser() {
+		if &conf.User == nil || len(conf.User) == 0 {
+			u, _ := user.Current()
+			conf.User = u.Username
+		}
+		params = append(params, "user.name="+conf.User)
+	}
+	if conf.ProxyUser != "" {
+		params = append(params, "doas="+url.QueryEscape(conf.ProxyUser))
+	}
+	if len(params) > 0 {
+		urlStr = urlStr + "?" + strings.Join(params, "&")
 	}
-	urlStr = urlStr + "?user.name=" + conf.User
 
 	u, err := url.Parse(urlStr)
 
@@ -49,3 +87,155 @@ func (conf *Configuration) GetNameNodeUrl() (*url.URL, error) {
 
 	return u, nil
 }
+
+// Returns the delegation token urlString to send, if any.
+func (conf *Configuration) delegationToken() string {
+	if conf.TokenSource != nil {
+		return conf.TokenSource.Token().UrlString
+	}
+	return conf.DelegationToken
+}
+
+// Returns the URL of the WebHDFS root, from Endpoint or from the first
+// namenode.  "/webhdfs/v1" is appended to Endpoint if it is not included.
+func (conf *Configuration) baseUrl() (*url.URL, error) {
+	if conf.Endpoint != "" {
+		u, err := url.Parse(conf.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("Configuration - invalid Endpoint %s: %s", conf.Endpoint, err.Error())
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("Configuration - Endpoint %s must be an absolute http(s) URL.", conf.Endpoint)
+		}
+		u.Path = strings.TrimRight(u.Path, "/")
+		if !strings.HasSuffix(u.Path, WebHdfsVer) {
+			u.Path = u.Path + WebHdfsVer
+		}
+		u.RawQuery, u.Fragment = "", ""
+		return u, nil
+	}
+
+	nameNodes := conf.NameNodes()
+	if len(nameNodes) == 0 {
+		return nil, errors.New("Configuration namenode address not set.")
+	}
+	scheme := "http"
+	if conf.UseTLS {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: nameNodes[0], Path: WebHdfsVer}, nil
+}
+
+// Returns true when the Authenticator or Basic credentials identify the
+// user, so user.name must not be sent.
+func (conf *Configuration) authenticatorIdentifiesUser() bool {
+	if conf.BasicAuthUser != "" {
+		return true
+	}
+	id, ok := conf.Authenticator.(UserIdentifier)
+	return ok && id.IdentifiesUser()
+}
+
+// Returns the address to connect to for datanode addr (host:port),
+// rewritten with DataNodeAddrs and DataNodeResolver.  Namenode
+// addresses are not rewritten.
+func (conf *Configuration) dataNodeAddr(addr string) string {
+	if conf.DataNodeAddrs == nil && conf.DataNodeResolver == nil {
+		return addr
+	}
+	for _, nn := range conf.NameNodes() {
+		if addr == nn {
+			return addr
+		}
+	}
+	if to, ok := conf.DataNodeAddrs[addr]; ok {
+		addr = to
+	} else if host, port, err := net.SplitHostPort(addr); err == nil {
+		if to, ok := conf.DataNodeAddrs[host]; ok {
+			if _, _, err := net.SplitHostPort(to); err == nil {
+				addr = to
+			} else {
+				addr = net.JoinHostPort(to, port)
+			}
+		}
+	}
+	if conf.DataNodeResolver != nil {
+		addr = conf.DataNodeResolver(addr)
+	}
+	return addr
+}
+
+// Returns the namenode addresses: Addr followed by Addrs, or the
+// host of Endpoint when it is set.
+func (conf *Configuration) NameNodes() []string {
+	if conf.Endpoint != "" {
+		if u, err := url.Parse(conf.Endpoint); err == nil && u.Host != "" {
+			return []string{u.Host}
+		}
+		return nil
+	}
+	var addrs []string
+	seen := make(map[string]bool)
+	for _, addr := range append([]string{conf.Addr}, conf.Addrs...) {
+		if addr != "" && !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// Loads PEM encoded TLS settings from files.  caFile is added to
+// TLSRootCAs, certFile and keyFile are loaded as a client certificate.
+// Empty file names are skipped.  UseTLS is turned on.
+func (conf *Configuration) LoadTLSFiles(caFile, certFile, keyFile string) error {
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+		if conf.TLSRootCAs == nil {
+			conf.TLSRootCAs = x509.NewCertPool()
+		}
+		if !conf.TLSRootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("LoadTLSFiles() - no certificate found in %s", caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		conf.TLSCertificates = append(conf.TLSCertificates, cert)
+	}
+	conf.UseTLS = true
+	return nil
+}
+
+// Returns the TLS config used to connect to host.  TLSServerName only
+// applies to the namenode, datanodes are verified against their own name.
+func (conf *Configuration) tlsConfig(host string) *tls.Config {
+	serverName := host
+	if conf.TLSServerName != "" {
+		for _, addr := range conf.NameNodes() {
+			if host == hostOnly(addr) {
+				serverName = conf.TLSServerName
+			}
+		}
+	}
+	return &tls.Config{
+		RootCAs:            conf.TLSRootCAs,
+		Certificates:       conf.TLSCertificates,
+		ServerName:         serverName,
+		MinVersion:         conf.TLSMinVersion,
+		InsecureSkipVerify: conf.TLSInsecureSkipVerify,
+	}
+}
+
+// Returns the host portion of a host:port address.
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -26,3 +26,95 @@ func Test_GetNameNodeUrl(t *testing.T) {
 		t.Errorf("Expecting param user.name=%s, but user.name=%s [url=%v]", conf.User, u.Query().Get("user.name"), u)
 	}
 }
+
+func Test_GetNameNodeUrl_DelegationToken(t *testing.T) {
+	conf := Configuration{Addr: "localhost:8080", User: "vvivien", DelegationToken: "JQAIaG9y1afae2radfaerzcqdt14AfeE="}
+	u, err := conf.GetNameNodeUrl()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Query().Get("delegation") != conf.DelegationToken {
+		t.Errorf("Expecting param delegation=%s, but delegation=%s [url=%v]", conf.DelegationToken, u.Query().Get("delegation"), u)
+	}
+	if u.Query().Get("user.name") != "" {
+		t.Errorf("Expecting no user.name param with delegation token, but got [url=%v]", u)
+	}
+}
+
+func Test_GetNameNodeUrl_TLS(t *testing.T) {
+	conf := Configuration{Addr: "localhost:50470", User: "vvivien", UseTLS: true}
+	u, err := conf.GetNameNodeUrl()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Scheme != "https" {
+		t.Errorf("Expecting url.Scheme https, but got %s", u.Scheme)
+	}
+}
+
+func Test_GetNameNodeUrl_ProxyUser(t *testing.T) {
+	conf := Configuration{Addr: "localhost:8080", User: "gateway", ProxyUser: "alice"}
+	u, err := conf.GetNameNodeUrl()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Query().Get("user.name") != "gateway" || u.Query().Get("doas") != "alice" {
+		t.Errorf("Expecting params user.name=gateway and doas=alice, but got [url=%v]", u)
+	}
+}
+
+func Test_GetNameNodeUrl_Endpoint(t *testing.T) {
+	for _, endpoint := range []string{
+		"https://gateway:8443/gateway/default",
+		"https://gateway:8443/gateway/default/webhdfs/v1/",
+	} {
+		conf := Configuration{Endpoint: endpoint, BasePath: "/data", BasicAuthUser: "guest"}
+		u, err := conf.GetNameNodeUrl()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Scheme != "https" || u.Host != "gateway:8443" {
+			t.Errorf("Expecting https://gateway:8443, but got [url=%v]", u)
+		}
+		if u.Path != "/gateway/default/webhdfs/v1/data" {
+			t.Errorf("Expecting path /gateway/default/webhdfs/v1/data, but got %s", u.Path)
+		}
+		if u.Query().Get("user.name") != "" {
+			t.Errorf("Expecting no user.name param with Basic credentials, but got [url=%v]", u)
+		}
+	}
+
+	conf := Configuration{Endpoint: "gateway:8443/gateway/default"}
+	if _, err := conf.GetNameNodeUrl(); err == nil {
+		t.Error("Expecting an error for an Endpoint without scheme.")
+	}
+}
+
+func Test_dataNodeAddr(t *testing.T) {
+	conf := Configuration{
+		Addr: "namenode:50070",
+		DataNodeAddrs: map[string]string{
+			"dn1:50075":      "localhost:50075",
+			"dn2":            "127.0.0.2",
+			"namenode:50070": "ignored:1",
+		},
+	}
+	for addr, expected := range map[string]string{
+		"dn1:50075":      "localhost:50075",
+		"dn2:50075":      "127.0.0.2:50075",
+		"dn3:50075":      "dn3:50075",
+		"namenode:50070": "namenode:50070",
+	} {
+		if got := conf.dataNodeAddr(addr); got != expected {
+			t.Errorf("Expecting %s rewritten to %s, but got %s", addr, expected, got)
+		}
+	}
+
+	conf.DataNodeResolver = func(addr string) string { return "resolved:" + addr[len(addr)-5:] }
+	if got := conf.dataNodeAddr("dn3:50075"); got != "resolved:50075" {
+		t.Errorf("Expecting resolver to be applied, but got %s", got)
+	}
+}
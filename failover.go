@@ -0,0 +1,117 @@
+package gowfs
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Tracks the namenodes of an HA pair and which one is active.
+// It is shared by a FileSystem and the views derived from it.
+type nameNodeList struct {
+	mu     sync.Mutex
+	addrs  []string
+	active int
+}
+
+func (nn *nameNodeList) current() (int, string) {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	return nn.active, nn.addrs[nn.active]
+}
+
+// Moves to the namenode after failed, unless another request already did.
+func (nn *nameNodeList) failover(failed int) {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	if nn.active == failed {
+		nn.active = (failed + 1) % len(nn.addrs)
+	}
+}
+
+func (nn *nameNodeList) contains(host string) bool {
+	for _, addr := range nn.addrs {
+		if addr == host {
+			return true
+		}
+	}
+	return false
+}
+
+// Sends namenode requests to the active namenode.  When a namenode is in
+// standby or refuses connections, the request is sent to the next one.
+// Other errors are only retried for idempotent (GET) requests.
+// Requests for other hosts (datanodes) are passed through.
+type failoverTransport struct {
+	nameNodes *nameNodeList
+	next      http.RoundTripper
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.nameNodes.contains(req.URL.Host) {
+		return t.next.RoundTrip(req)
+	}
+
+	// a request body that can't be replayed is only sent once.
+	attempts := len(t.nameNodes.addrs)
+	if req.Body != nil && req.GetBody == nil {
+		attempts = 1
+	}
+
+	var rsp *http.Response
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		idx, addr := t.nameNodes.current()
+		r := cloneRequest(req)
+		u := *req.URL
+		u.Host = addr
+		r.URL, r.Host = &u, ""
+		if attempt > 0 && req.GetBody != nil {
+			if r.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		rsp, err = t.next.RoundTrip(r)
+		if err != nil {
+			if !isDialError(err) && req.Method != "GET" {
+				return nil, err
+			}
+		} else if !isStandbyResponse(rsp) {
+			return rsp, nil
+		} else if attempt < attempts-1 {
+			rsp.Body.Close()
+		}
+		t.nameNodes.failover(idx)
+	}
+	return rsp, err
+}
+
+// Returns true when the connection could not be established (i.e.
+// connection refused), so the request never reached the server.
+func isDialError(err error) bool {
+	for err != nil {
+		if opErr, ok := err.(*net.OpError); ok {
+			return opErr.Op == "dial"
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// Returns true when rsp carries a StandbyException.  The response body
+// is restored so it can still be read by the caller.
+func isStandbyResponse(rsp *http.Response) bool {
+	if rsp.StatusCode < 400 {
+		return false
+	}
+	if re, ok := peekRemoteException(rsp).(RemoteException); ok {
+		return re.Exception == "StandbyException" || strings.HasSuffix(re.JavaClassName, ".StandbyException")
+	}
+	return false
+}
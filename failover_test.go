@@ -0,0 +1,92 @@
+package gowfs
+
+import "fmt"
+import "net/url"
+import "net/http"
+import "net/http/httptest"
+import "sync/atomic"
+
+import "testing"
+
+const standbyExceptionRsp = `
+{
+  "RemoteException":
+  {
+    "exception"    : "StandbyException",
+    "javaClassName": "org.apache.hadoop.ipc.StandbyException",
+    "message"      : "Operation category READ is not supported in state standby"
+  }
+}
+`
+
+func Test_NameNodeFailover(t *testing.T) {
+	var standbyHits, activeHits int32
+	standby := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&standbyHits, 1)
+		rsp.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(rsp, standbyExceptionRsp)
+	}))
+	defer standby.Close()
+	active := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&activeHits, 1)
+		fmt.Fprint(rsp, listStatusRsp)
+	}))
+	defer active.Close()
+
+	standbyUrl, _ := url.Parse(standby.URL)
+	activeUrl, _ := url.Parse(active.URL)
+	conf := Configuration{Addr: standbyUrl.Host, Addrs: []string{activeUrl.Host}}
+	fs, _ := NewFileSystem(conf)
+
+	for i := 0; i < 2; i++ {
+		stats, err := fs.ListStatus(Path{Name: "/test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats) != 2 {
+			t.Fatal("ListStatus() - not returning data from active namenode.")
+		}
+	}
+	if fs.ActiveNameNode() != activeUrl.Host {
+		t.Errorf("Expecting active namenode %s, but got %s", activeUrl.Host, fs.ActiveNameNode())
+	}
+	if standbyHits != 1 || activeHits != 2 {
+		t.Errorf("Expecting 1 standby and 2 active requests, but got %d and %d", standbyHits, activeHits)
+	}
+}
+
+func Test_NameNodeFailover_ConnectionRefused(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downUrl, _ := url.Parse(down.URL)
+	down.Close()
+
+	active := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rsp, `{"boolean": true}`)
+	}))
+	defer active.Close()
+	activeUrl, _ := url.Parse(active.URL)
+
+	fs, _ := NewFileSystem(Configuration{Addrs: []string{downUrl.Host, activeUrl.Host}})
+	ok, err := fs.MkDirs(Path{Name: "/testing"}, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("MkDirs() - not sent to the available namenode.")
+	}
+}
+
+func Test_NameNodeFailover_AllStandby(t *testing.T) {
+	standby := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(rsp, standbyExceptionRsp)
+	}))
+	defer standby.Close()
+	standbyUrl, _ := url.Parse(standby.URL)
+
+	fs, _ := NewFileSystem(Configuration{Addrs: []string{standbyUrl.Host, "127.0.0.1:1"}})
+	_, err := fs.GetFileStatus(Path{Name: "/test"})
+	if err == nil {
+		t.Fatal("Expecting error when no namenode is active.")
+	}
+}
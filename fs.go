@@ -5,11 +5,16 @@ See https://github.com/vladimirvivien/gowfs.
 */
 package gowfs
 
+import "context"
+import "crypto/tls"
 import "encoding/json"
+import "errors"
 import "net"
 import "net/http"
 import "net/url"
+import "sync"
 import "io/ioutil"
+import "time"
 
 const (
 	OP_OPEN                  = "OPEN"
@@ -32,6 +37,44 @@ const (
 	OP_GETDELEGATIONTOKENS   = "GETDELEGATIONTOKENS"
 	OP_RENEWDELEGATIONTOKEN  = "RENEWDELEGATIONTOKEN"
 	OP_CANCELDELEGATIONTOKEN = "CANCELDELEGATIONTOKEN"
+	OP_GETHOMEDIRECTORY      = "GETHOMEDIRECTORY"
+	OP_TRUNCATE              = "TRUNCATE"
+	OP_SETXATTR              = "SETXATTR"
+	OP_REMOVEXATTR           = "REMOVEXATTR"
+	OP_GETXATTRS             = "GETXATTRS"
+	OP_LISTXATTRS            = "LISTXATTRS"
+	OP_GETACLSTATUS          = "GETACLSTATUS"
+	OP_SETACL                = "SETACL"
+	OP_MODIFYACLENTRIES      = "MODIFYACLENTRIES"
+	OP_REMOVEACLENTRIES      = "REMOVEACLENTRIES"
+	OP_REMOVEDEFAULTACL      = "REMOVEDEFAULTACL"
+	OP_REMOVEACL             = "REMOVEACL"
+	OP_GETALLSTORAGEPOLICY   = "GETALLSTORAGEPOLICY"
+	OP_GETSTORAGEPOLICY      = "GETSTORAGEPOLICY"
+	OP_SETSTORAGEPOLICY      = "SETSTORAGEPOLICY"
+	OP_UNSETSTORAGEPOLICY    = "UNSETSTORAGEPOLICY"
+	OP_SATISFYSTORAGEPOLICY  = "SATISFYSTORAGEPOLICY"
+	OP_ENABLEECPOLICY        = "ENABLEECPOLICY"
+	OP_DISABLEECPOLICY       = "DISABLEECPOLICY"
+	OP_SETECPOLICY           = "SETECPOLICY"
+	OP_GETECPOLICY           = "GETECPOLICY"
+	OP_UNSETECPOLICY         = "UNSETECPOLICY"
+	OP_GETQUOTAUSAGE         = "GETQUOTAUSAGE"
+	OP_SETQUOTA              = "SETQUOTA"
+	OP_SETQUOTABYSTORAGETYPE = "SETQUOTABYSTORAGETYPE"
+)
+
+// Snapshot operations.
+const (
+	OP_ALLOWSNAPSHOT                 = "ALLOWSNAPSHOT"
+	OP_DISALLOWSNAPSHOT              = "DISALLOWSNAPSHOT"
+	OP_CREATESNAPSHOT                = "CREATESNAPSHOT"
+	OP_RENAMESNAPSHOT                = "RENAMESNAPSHOT"
+	OP_DELETESNAPSHOT                = "DELETESNAPSHOT"
+	OP_GETSNAPSHOTTABLEDIRECTORYLIST = "GETSNAPSHOTTABLEDIRECTORYLIST"
+	OP_GETSNAPSHOTLIST               = "GETSNAPSHOTLIST"
+	OP_GETSNAPSHOTDIFF               = "GETSNAPSHOTDIFF"
+	OP_GETSNAPSHOTDIFFLISTING        = "GETSNAPSHOTDIFFLISTING"
 )
 
 // Hack for in-lining multi-value functions
@@ -41,33 +84,193 @@ func µ(v ...interface{}) []interface{} {
 
 // This type maps fields and functions to HDFS's FileSystem class.
 type FileSystem struct {
-	Config    Configuration
-	client    http.Client
-	transport *http.Transport
+	Config       Configuration
+	client       http.Client
+	transport    *http.Transport
+	roundTripper http.RoundTripper // transport, wrapped by Config.Middleware, Authenticator, failover and retries
+	nameNodes    *nameNodeList     // HA namenodes, nil with a single namenode
+	httpFS       *int32            // 1 once the server is detected as HttpFS, shared with views
+	home         *homeDirectory    // home directory relative paths resolve against
+}
+
+// Home directory of the user, fetched once with GETHOMEDIRECTORY.
+type homeDirectory struct {
+	mu   sync.Mutex
+	path string
 }
 
 func NewFileSystem(conf Configuration) (*FileSystem, error) {
 	fs := &FileSystem{
 		Config: conf,
+		httpFS: new(int32),
+		home:   &homeDirectory{},
+	}
+	dial := func(netw, addr string) (net.Conn, error) {
+		// datanode URLs keep their host, verified by TLS and sent as Host.
+		c, err := net.DialTimeout(netw, conf.dataNodeAddr(addr), conf.ConnectionTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return c, nil
 	}
 	fs.transport = &http.Transport{
-		Dial: func(netw, addr string) (net.Conn, error) {
-			c, err := net.DialTimeout(netw, addr, conf.ConnectionTimeout)
+		Dial: dial,
+		// used for namenode and datanode (redirect) https connections
+		DialTLS: func(netw, addr string) (net.Conn, error) {
+			c, err := dial(netw, addr)
 			if err != nil {
 				return nil, err
 			}
-
-			return c, nil
+			tc := tls.Client(c, conf.tlsConfig(hostOnly(addr)))
+			if conf.ConnectionTimeout > 0 {
+				tc.SetDeadline(time.Now().Add(conf.ConnectionTimeout))
+			}
+			if err := tc.Handshake(); err != nil {
+				c.Close()
+				return nil, err
+			}
+			tc.SetDeadline(time.Time{})
+			return tc, nil
 		},
 		MaxIdleConnsPerHost:   conf.MaxIdleConnsPerHost,
 		ResponseHeaderTimeout: conf.ResponseHeaderTimeout,
 	}
+	fs.roundTripper = fs.transport
+	if conf.Transport != nil {
+		fs.roundTripper = conf.Transport
+	}
+	fs.roundTripper = chainMiddleware(fs.roundTripper, conf.Middleware)
+	if conf.BasicAuthUser != "" {
+		fs.roundTripper = &basicAuthTransport{
+			user:     conf.BasicAuthUser,
+			password: conf.BasicAuthPassword,
+			hosts:    conf.NameNodes(),
+			next:     fs.roundTripper,
+		}
+	}
+	if auth, ok := conf.Authenticator.(nameNodeAuthenticator); ok {
+		fs.roundTripper = auth.wrapNameNodes(fs.roundTripper, conf.NameNodes())
+	} else if conf.Authenticator != nil {
+		fs.roundTripper = conf.Authenticator.Wrap(fs.roundTripper)
+	}
+	if addrs := conf.NameNodes(); len(addrs) > 1 {
+		fs.nameNodes = &nameNodeList{addrs: addrs}
+		fs.roundTripper = &failoverTransport{nameNodes: fs.nameNodes, next: fs.roundTripper}
+	}
+	if conf.RetryPolicy != nil {
+		fs.roundTripper = &retryTransport{policy: conf.RetryPolicy, next: fs.roundTripper}
+	}
 	fs.client = http.Client{
-		Transport: fs.transport,
+		Transport: fs.roundTripper,
+		// redirects followed by the client (i.e. Open) go to datanodes.
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			host := req.URL.Host
+			fs.prepareDatanodeUrl(req.URL)
+			req.Host = host
+			return nil
+		},
 	}
 	return fs, nil
 }
 
+// Returns the address of the namenode requests are currently sent to.
+// With HA namenodes, this is the last namenode known to be active.
+func (fs *FileSystem) ActiveNameNode() string {
+	if fs.nameNodes == nil {
+		if addrs := fs.Config.NameNodes(); len(addrs) > 0 {
+			return addrs[0]
+		}
+		return ""
+	}
+	_, addr := fs.nameNodes.current()
+	return addr
+}
+
+// Returns a FileSystem that shares the transport of fs, but authenticates
+// all operations with the specified delegation token instead of user.name.
+func (fs *FileSystem) WithToken(token Token) *FileSystem {
+	view := *fs
+	view.Config.DelegationToken = token.UrlString
+	view.home = &homeDirectory{} // may be another user
+	return &view
+}
+
+// Returns a FileSystem that shares the transport of fs, but authenticates
+// all operations with the current token of src (see TokenManager).
+func (fs *FileSystem) WithTokenSource(src TokenSource) *FileSystem {
+	view := *fs
+	view.Config.TokenSource = src
+	view.home = &homeDirectory{} // may be another user
+	return &view
+}
+
+// Returns a FileSystem that shares the transport of fs, but runs all
+// operations on behalf of the specified user (doas).  The authenticated
+// user must be allowed to impersonate it (hadoop.proxyuser.* settings).
+func (fs *FileSystem) As(user string) *FileSystem {
+	view := *fs
+	view.Config.ProxyUser = user
+	view.home = &homeDirectory{} // may be another user
+	return &view
+}
+
+// Prepares the datanode URL returned by a namenode redirect before
+// it is requested.  The delegation token and proxy user are attached if
+// missing.  Its address (see DataNodeAddrs) is rewritten by the dialer
+// of the built-in transport, here only when Config.Transport replaces it.
+func (fs *FileSystem) prepareDatanodeUrl(u *url.URL) {
+	if fs.Config.Transport != nil {
+		u.Host = fs.Config.dataNodeAddr(u.Host)
+	}
+	q := u.Query()
+	changed := false
+	if token := fs.Config.delegationToken(); token != "" && q.Get("delegation") == "" {
+		q.Set("delegation", token)
+		changed = true
+	}
+	if fs.Config.ProxyUser != "" && q.Get("doas") == "" {
+		q.Set("doas", fs.Config.ProxyUser)
+		changed = true
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+}
+
+// Builds the URL of a request for the operation in params on p.  A
+// relative p is resolved against the home directory (see ResolvePath).
+func (fs *FileSystem) requestUrl(ctx context.Context, p *Path, params *map[string]string) (*url.URL, error) {
+	if p != nil {
+		resolved, err := fs.ResolvePathContext(ctx, *p)
+		if err != nil {
+			return nil, err
+		}
+		p = &resolved
+	}
+	return buildRequestUrl(fs.Config, p, params)
+}
+
+// Sends a PUT operation on p that answers with no content (i.e.
+// SETXATTR, SETACL).
+func (fs *FileSystem) putOperation(ctx context.Context, p Path, params map[string]string) (bool, error) {
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return false, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
+	_, err = requestHdfsData(fs.client, *req)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // Builds the canonical URL used for remote request
 func buildRequestUrl(conf Configuration, p *Path, params *map[string]string) (*url.URL, error) {
 	u, err := conf.GetNameNodeUrl()
@@ -1,7 +1,21 @@
 package gowfs
 
+import "bytes"
+import "crypto/ecdsa"
+import "crypto/elliptic"
+import "crypto/rand"
+import "crypto/tls"
+import "crypto/x509"
+import "crypto/x509/pkix"
+import "fmt"
+import "io/ioutil"
+import "log"
+import "math/big"
+import "net/http"
+import "net/http/httptest"
 import "net/url"
 import "testing"
+import "time"
 import "os/user"
 
 func Test_NewFileSystem(t *testing.T) {
@@ -53,3 +67,159 @@ func Test_buildRequestUrl(t *testing.T) {
 		t.Errorf("Expecting url [%v], but got [%v]", url1.String(), u.String())
 	}
 }
+
+func Test_NewFileSystem_TLS(t *testing.T) {
+	// datanode, reached through the https redirect
+	server1 := httptest.NewTLSServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		if string(data) != "Hello webhdfs users!" {
+			log.Fatalf("Expected data not posted to server. Server got %v", string(data))
+		}
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer server1.Close()
+
+	// namenode
+	server2 := httptest.NewTLSServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.Header().Set("Location", server1.URL+req.URL.String())
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer server2.Close()
+	t.Logf("Test_NewFileSystem_TLS - Started httptest.Server on %v", server2.URL)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server2.Certificate())
+
+	url, _ := url.Parse(server2.URL)
+	conf := Configuration{
+		Addr:          url.Host,
+		UseTLS:        true,
+		TLSRootCAs:    pool,
+		TLSServerName: "example.com",
+		TLSMinVersion: tls.VersionTLS12,
+	}
+	fs, _ := NewFileSystem(conf)
+
+	ok, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("Create() - file not created over TLS.")
+	}
+
+	// untrusted server must be rejected
+	conf.TLSRootCAs = x509.NewCertPool()
+	fs, _ = NewFileSystem(conf)
+	if _, err := fs.ListStatus(Path{Name: "/"}); err == nil {
+		t.Fatal("Expecting certificate verification error.")
+	}
+}
+
+func Test_DataNodeAddrs_TLS(t *testing.T) {
+	datanode, pool := newTLSServerFor("datanode.cluster.internal", http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if req.Host != "datanode.cluster.internal:50475" {
+			log.Fatalf("Expecting Host datanode.cluster.internal:50475, but got %v", req.Host)
+		}
+		if req.Method == "GET" {
+			fmt.Fprint(rsp, "Hello webhdfs users!")
+			return
+		}
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer datanode.Close()
+	namenode := httptest.NewTLSServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.Header().Set("Location", "https://datanode.cluster.internal:50475"+req.URL.RequestURI())
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer namenode.Close()
+	pool.AddCert(namenode.Certificate())
+
+	nnUrl, _ := url.Parse(namenode.URL)
+	dnUrl, _ := url.Parse(datanode.URL)
+	fs, _ := NewFileSystem(Configuration{
+		Addr:          nnUrl.Host,
+		User:          "hdfs",
+		UseTLS:        true,
+		TLSRootCAs:    pool,
+		DataNodeAddrs: map[string]string{"datanode.cluster.internal:50475": dnUrl.Host},
+	})
+
+	// the datanode certificate is verified for its own name.
+	if _, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := fs.Open(Path{Name: "/testing/newfile"}, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if data, _ := ioutil.ReadAll(reader); string(data) != "Hello webhdfs users!" {
+		t.Errorf("Expecting data from the rewritten datanode, but got %v", string(data))
+	}
+}
+
+func Test_As(t *testing.T) {
+	checkDoas := func(req *http.Request) {
+		if req.URL.Query().Get("doas") != "alice" {
+			log.Fatalf("Expecting param doas=alice, but got [url=%v]", req.URL)
+		}
+	}
+	datanode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		checkDoas(req)
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer datanode.Close()
+	// the redirect drops doas, it must be added back by the client.
+	namenode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		checkDoas(req)
+		rsp.Header().Set("Location", datanode.URL+req.URL.Path+"?op=CREATE")
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer namenode.Close()
+
+	u, _ := url.Parse(namenode.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, User: "gateway"})
+	afs := fs.As("alice")
+
+	if fs.Config.ProxyUser != "" {
+		t.Fatal("As() - original FileSystem should not be modified.")
+	}
+
+	_, err := afs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Starts a TLS server with a self-signed certificate for name only, and
+// returns it with a pool trusting the certificate.
+func newTLSServerFor(name string, handler http.Handler) (*httptest.Server, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return server, pool
+}
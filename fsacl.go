@@ -0,0 +1,228 @@
+package gowfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Scope of an ACL entry: access entries apply to the path, default
+// entries are inherited by new children of a directory.
+type AclScope string
+
+const (
+	AclScopeAccess  AclScope = "access"
+	AclScopeDefault AclScope = "default"
+)
+
+// Type of an ACL entry.
+type AclType string
+
+const (
+	AclUser  AclType = "user"
+	AclGroup AclType = "group"
+	AclMask  AclType = "mask"
+	AclOther AclType = "other"
+)
+
+// ACL entry, in the [default:]type:name[:permission] form used by
+// 'hdfs dfs -setfacl' (i.e. user:alice:rwx, default:group::r-x).  Name is
+// empty for the owner, owning group, mask and other entries.  Permission
+// (i.e. r-x) is empty in entries to remove.
+type AclEntry struct {
+	Scope      AclScope // AclScopeAccess when empty
+	Type       AclType
+	Name       string
+	Permission string
+}
+
+// ACL spec, a list of entries separated by commas.
+type AclSpec []AclEntry
+
+var aclPermissionRegexp = regexp.MustCompile(`^[r-][w-][x-]$`)
+
+// Parses an ACL entry such as user:alice:rwx or default:group::r-x.
+func ParseAclEntry(s string) (AclEntry, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	entry := AclEntry{Scope: AclScopeAccess}
+	if strings.EqualFold(parts[0], string(AclScopeDefault)) {
+		entry.Scope = AclScopeDefault
+		parts = parts[1:]
+	}
+	if len(parts) < 1 || len(parts) > 3 {
+		return AclEntry{}, fmt.Errorf("ParseAclEntry() - invalid ACL entry %q.", s)
+	}
+
+	entry.Type = AclType(strings.ToLower(parts[0]))
+	switch entry.Type {
+	case AclUser, AclGroup, AclMask, AclOther:
+	default:
+		return AclEntry{}, fmt.Errorf("ParseAclEntry() - invalid ACL entry type in %q.", s)
+	}
+	if len(parts) > 1 {
+		entry.Name = parts[1]
+	}
+	if entry.Name != "" && (entry.Type == AclMask || entry.Type == AclOther) {
+		return AclEntry{}, fmt.Errorf("ParseAclEntry() - %s entries can't have a name in %q.", entry.Type, s)
+	}
+	if len(parts) > 2 {
+		entry.Permission = parts[2]
+		if !aclPermissionRegexp.MatchString(entry.Permission) {
+			return AclEntry{}, fmt.Errorf("ParseAclEntry() - invalid permission in %q.", s)
+		}
+	}
+	return entry, nil
+}
+
+// Parses an ACL spec such as user:alice:rwx,default:group::r-x.
+func ParseAclSpec(s string) (AclSpec, error) {
+	var spec AclSpec
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		entry, err := ParseAclEntry(item)
+		if err != nil {
+			return nil, err
+		}
+		spec = append(spec, entry)
+	}
+	return spec, nil
+}
+
+// Returns the entry in the [default:]type:name[:permission] form.
+func (e AclEntry) String() string {
+	s := string(e.Type) + ":" + e.Name
+	if e.Scope == AclScopeDefault {
+		s = "default:" + s
+	}
+	if e.Permission != "" {
+		s = s + ":" + e.Permission
+	}
+	return s
+}
+
+// Returns the entries separated by commas.
+func (spec AclSpec) String() string {
+	entries := make([]string, len(spec))
+	for i, e := range spec {
+		entries[i] = e.String()
+	}
+	return strings.Join(entries, ",")
+}
+
+func (e AclEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+func (e *AclEntry) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	entry, err := ParseAclEntry(s)
+	if err != nil {
+		return err
+	}
+	*e = entry
+	return nil
+}
+
+// Returns the ACL of the specified path.
+// See HDFS FileSystem.getAclStatus()
+func (fs *FileSystem) GetAclStatus(p Path) (AclStatus, error) {
+	return fs.GetAclStatusContext(context.Background(), p)
+}
+
+// GetAclStatus() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetAclStatusContext(ctx context.Context, p Path) (AclStatus, error) {
+	params := map[string]string{"op": OP_GETACLSTATUS}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return AclStatus{}, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return AclStatus{}, err
+	}
+
+	return hdfsData.AclStatus, nil
+}
+
+// Replaces the ACL of the specified path.  The spec must include the
+// user, group and other entries.
+// See HDFS FileSystem.setAcl()
+func (fs *FileSystem) SetAcl(p Path, spec AclSpec) (bool, error) {
+	return fs.SetAclContext(context.Background(), p, spec)
+}
+
+// SetAcl() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetAclContext(ctx context.Context, p Path, spec AclSpec) (bool, error) {
+	return fs.aclUpdate(ctx, p, OP_SETACL, spec)
+}
+
+// Adds or updates the specified ACL entries, other entries are kept.
+// See HDFS FileSystem.modifyAclEntries()
+func (fs *FileSystem) ModifyAclEntries(p Path, spec AclSpec) (bool, error) {
+	return fs.ModifyAclEntriesContext(context.Background(), p, spec)
+}
+
+// ModifyAclEntries() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) ModifyAclEntriesContext(ctx context.Context, p Path, spec AclSpec) (bool, error) {
+	return fs.aclUpdate(ctx, p, OP_MODIFYACLENTRIES, spec)
+}
+
+// Removes the specified ACL entries.  Permissions in spec are ignored.
+// See HDFS FileSystem.removeAclEntries()
+func (fs *FileSystem) RemoveAclEntries(p Path, spec AclSpec) (bool, error) {
+	return fs.RemoveAclEntriesContext(context.Background(), p, spec)
+}
+
+// RemoveAclEntries() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) RemoveAclEntriesContext(ctx context.Context, p Path, spec AclSpec) (bool, error) {
+	entries := make(AclSpec, len(spec))
+	for i, e := range spec {
+		e.Permission = ""
+		entries[i] = e
+	}
+	return fs.aclUpdate(ctx, p, OP_REMOVEACLENTRIES, entries)
+}
+
+// Removes the default ACL entries of the specified directory.
+// See HDFS FileSystem.removeDefaultAcl()
+func (fs *FileSystem) RemoveDefaultAcl(p Path) (bool, error) {
+	return fs.RemoveDefaultAclContext(context.Background(), p)
+}
+
+// RemoveDefaultAcl() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) RemoveDefaultAclContext(ctx context.Context, p Path) (bool, error) {
+	return fs.aclUpdate(ctx, p, OP_REMOVEDEFAULTACL, nil)
+}
+
+// Removes all extended ACL entries, the base entries are kept.
+// See HDFS FileSystem.removeAcl()
+func (fs *FileSystem) RemoveAcl(p Path) (bool, error) {
+	return fs.RemoveAclContext(context.Background(), p)
+}
+
+// RemoveAcl() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) RemoveAclContext(ctx context.Context, p Path) (bool, error) {
+	return fs.aclUpdate(ctx, p, OP_REMOVEACL, nil)
+}
+
+// Sends the ACL operation op, with spec as aclspec when it is not nil.
+func (fs *FileSystem) aclUpdate(ctx context.Context, p Path, op string, spec AclSpec) (bool, error) {
+	params := map[string]string{"op": op}
+	if spec != nil {
+		if len(spec) == 0 {
+			return false, fmt.Errorf("%s - the ACL spec cannot be empty.", op)
+		}
+		params["aclspec"] = spec.String()
+	}
+	return fs.putOperation(ctx, p, params)
+}
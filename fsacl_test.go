@@ -0,0 +1,176 @@
+package gowfs
+
+import "encoding/json"
+import "fmt"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "strings"
+import "sync"
+import "testing"
+
+func Test_ParseAclSpec(t *testing.T) {
+	spec, err := ParseAclSpec("user:alice:rwx,default:group::r-x,mask::r--,other::---")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := AclSpec{
+		{Scope: AclScopeAccess, Type: AclUser, Name: "alice", Permission: "rwx"},
+		{Scope: AclScopeDefault, Type: AclGroup, Permission: "r-x"},
+		{Scope: AclScopeAccess, Type: AclMask, Permission: "r--"},
+		{Scope: AclScopeAccess, Type: AclOther, Permission: "---"},
+	}
+	if len(spec) != len(expected) {
+		t.Fatalf("Expecting %d entries, but got %v", len(expected), spec)
+	}
+	for i := range spec {
+		if spec[i] != expected[i] {
+			t.Errorf("Expecting entry %v, but got %v", expected[i], spec[i])
+		}
+	}
+	if spec.String() != "user:alice:rwx,default:group::r-x,mask::r--,other::---" {
+		t.Errorf("Expecting the spec to round-trip, but got %s", spec.String())
+	}
+
+	removal, err := ParseAclSpec("user:bob,default:user:bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removal.String() != "user:bob,default:user:bob" {
+		t.Errorf("Expecting the removal spec to round-trip, but got %s", removal.String())
+	}
+
+	for _, invalid := range []string{"owner:alice:rwx", "user:alice:rwz", "mask:m:rwx", "user:a:rwx:x"} {
+		if _, err := ParseAclSpec(invalid); err == nil {
+			t.Errorf("Expecting an error for %q", invalid)
+		}
+	}
+}
+
+func Test_Acl(t *testing.T) {
+	server := mockServerFor_Acl()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	p := Path{Name: "/data"}
+
+	spec, _ := ParseAclSpec("user:alice:rwx,default:user:alice:r-x")
+	if _, err := fs.ModifyAclEntries(p, spec); err != nil {
+		t.Fatal(err)
+	}
+	status, err := fs.GetAclStatus(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Owner != "hdfs" || len(status.Entries) != 2 || status.Entries[1].Scope != AclScopeDefault {
+		t.Errorf("Expecting access and default entries for alice, but got %v", status)
+	}
+
+	if _, err := fs.RemoveAclEntries(p, spec[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.RemoveDefaultAcl(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.SetAcl(p, AclSpec{}); err == nil {
+		t.Error("Expecting an error for an empty ACL spec.")
+	}
+	if _, err := fs.RemoveAcl(p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// ACLs of /data (holding a.txt and sub/b.txt), stored as received.
+func mockServerFor_Acl() *httptest.Server {
+	var mu sync.Mutex
+	dirs := map[string][]string{
+		"/data":     {"a.txt", "sub"},
+		"/data/sub": {"b.txt"},
+	}
+	acls := map[string][]string{"/data": nil, "/data/a.txt": nil, "/data/sub": nil, "/data/sub/b.txt": nil}
+	status := func(name string) string {
+		typ := "FILE"
+		if _, ok := dirs[name]; ok {
+			typ = "DIRECTORY"
+		}
+		return fmt.Sprintf(`{"pathSuffix": "%s", "type": "%s"}`, name[strings.LastIndex(name, "/")+1:], typ)
+	}
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		p := strings.TrimPrefix(req.URL.Path, WebHdfsVer)
+		entries, ok := acls[p]
+		if !ok {
+			log.Fatalf("Unexpected path %v", p)
+		}
+		q := req.URL.Query()
+		var spec []string
+		if q.Get("aclspec") != "" {
+			spec = strings.Split(q.Get("aclspec"), ",")
+		}
+		switch q.Get("op") {
+		case OP_GETFILESTATUS:
+			fmt.Fprintf(rsp, `{"FileStatus": %s}`, status(p))
+		case OP_LISTSTATUS:
+			var statuses []string
+			for _, child := range dirs[p] {
+				statuses = append(statuses, status(p+"/"+child))
+			}
+			fmt.Fprintf(rsp, `{"FileStatuses": {"FileStatus": [%s]}}`, strings.Join(statuses, ","))
+		case OP_GETACLSTATUS:
+			encoded, _ := json.Marshal(entries)
+			if entries == nil {
+				encoded = []byte("[]")
+			}
+			fmt.Fprintf(rsp, `{"AclStatus": {"entries": %s, "group": "supergroup", "owner": "hdfs", "permission": "775", "stickyBit": false}}`, encoded)
+		case OP_SETACL, OP_MODIFYACLENTRIES:
+			for _, e := range spec {
+				if _, _, _, ok := splitTestAclEntry(e); !ok {
+					log.Fatalf("Invalid aclspec entry %v", e)
+				}
+			}
+			acls[p] = spec
+		case OP_REMOVEACLENTRIES:
+			var kept []string
+			for _, e := range entries {
+				remove := false
+				for _, r := range spec {
+					if strings.HasPrefix(e, r+":") {
+						remove = true
+					}
+				}
+				if !remove {
+					kept = append(kept, e)
+				}
+			}
+			acls[p] = kept
+		case OP_REMOVEDEFAULTACL:
+			var kept []string
+			for _, e := range entries {
+				if !strings.HasPrefix(e, "default:") {
+					kept = append(kept, e)
+				}
+			}
+			acls[p] = kept
+		case OP_REMOVEACL:
+			acls[p] = nil
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+// Splits a [default:]type:name:perm entry with a permission.
+func splitTestAclEntry(e string) (string, string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(e, "default:"), ":")
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
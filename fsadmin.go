@@ -1,24 +1,37 @@
 package gowfs
 
+import "context"
 import "fmt"
 import "os"
 import "net/http"
+import "path"
 import "strconv"
+import "strings"
 
 // Renames the specified path resource to a new name.
 // See HDFS FileSystem.rename()
 func (fs *FileSystem) Rename(source Path, destination Path) (bool, error) {
+	return fs.RenameContext(context.Background(), source, destination)
+}
+
+// Rename() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) RenameContext(ctx context.Context, source Path, destination Path) (bool, error) {
 	if source.Name == "" || destination.Name == "" {
 		return false, fmt.Errorf("Rename() - params source and destination cannot be empty.")
 	}
 
+	destination, err := fs.ResolvePathContext(ctx, destination)
+	if err != nil {
+		return false, err
+	}
+
 	params := map[string]string{"op": OP_RENAME, "destination": destination.Name}
-	u, err := buildRequestUrl(fs.Config, &source, &params)
+	u, err := fs.requestUrl(ctx, &source, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return false, err
@@ -30,6 +43,11 @@ func (fs *FileSystem) Rename(source Path, destination Path) (bool, error) {
 //Deletes the specified path.
 //See HDFS FileSystem.delete()
 func (fs *FileSystem) Delete(path Path, recursive bool) (bool, error) {
+	return fs.DeleteContext(context.Background(), path, recursive)
+}
+
+// Delete() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) DeleteContext(ctx context.Context, path Path, recursive bool) (bool, error) {
 	if path.Name == "" {
 		return false, fmt.Errorf("Delete() - param path cannot be empty.")
 	}
@@ -37,12 +55,12 @@ func (fs *FileSystem) Delete(path Path, recursive bool) (bool, error) {
 		"op":        OP_DELETE,
 		"recursive": strconv.FormatBool(recursive)}
 
-	u, err := buildRequestUrl(fs.Config, &path, &params)
+	u, err := fs.requestUrl(ctx, &path, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("DELETE", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "DELETE", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return false, err
@@ -54,6 +72,11 @@ func (fs *FileSystem) Delete(path Path, recursive bool) (bool, error) {
 // Sets the permission for the specified path.
 // See FileSystem.setPermission()
 func (fs *FileSystem) SetPermission(path Path, permission os.FileMode) (bool, error) {
+	return fs.SetPermissionContext(context.Background(), path, permission)
+}
+
+// SetPermission() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetPermissionContext(ctx context.Context, path Path, permission os.FileMode) (bool, error) {
 	if path.Name == "" {
 		return false, fmt.Errorf("SetPermission() - param path cannot be empty.")
 	}
@@ -64,12 +87,12 @@ func (fs *FileSystem) SetPermission(path Path, permission os.FileMode) (bool, er
 		"op":         OP_SETPERMISSION,
 		"permission": strconv.FormatInt(int64(permission), 8)}
 
-	u, err := buildRequestUrl(fs.Config, &path, &params)
+	u, err := fs.requestUrl(ctx, &path, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	rsp, err := fs.client.Do(req)
 	if err != nil {
 		return false, err
@@ -84,6 +107,11 @@ func (fs *FileSystem) SetPermission(path Path, permission os.FileMode) (bool, er
 //Sets owner for the specified path.
 //See HDFS FileSystem.setOwner()
 func (fs *FileSystem) SetOwner(path Path, owner string, group string) (bool, error) {
+	return fs.SetOwnerContext(context.Background(), path, owner, group)
+}
+
+// SetOwner() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetOwnerContext(ctx context.Context, path Path, owner string, group string) (bool, error) {
 	if path.Name == "" {
 		return false, fmt.Errorf("SetOwner() - param path cannot be empty.")
 	}
@@ -92,12 +120,12 @@ func (fs *FileSystem) SetOwner(path Path, owner string, group string) (bool, err
 		"owner": owner,
 		"group": group}
 
-	u, err := buildRequestUrl(fs.Config, &path, &params)
+	u, err := fs.requestUrl(ctx, &path, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	rsp, err := fs.client.Do(req)
 	if err != nil {
 		return false, err
@@ -109,9 +137,15 @@ func (fs *FileSystem) SetOwner(path Path, owner string, group string) (bool, err
 	return true, nil
 }
 
-// Sets replication factor for given path.
+// Sets replication factor for given path.  Erasure-coded files have no
+// replication factor, an ErasureCodedFileError is returned for them.
 // See HDFS FileSystem.setReplication()
 func (fs *FileSystem) SetReplication(path Path, replication uint16) (bool, error) {
+	return fs.SetReplicationContext(context.Background(), path, replication)
+}
+
+// SetReplication() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetReplicationContext(ctx context.Context, path Path, replication uint16) (bool, error) {
 	if path.Name == "" {
 		return false, fmt.Errorf("SetReplication() - param path cannot be empty.")
 	}
@@ -122,22 +156,37 @@ func (fs *FileSystem) SetReplication(path Path, replication uint16) (bool, error
 		"op":          OP_SETREPLICATION,
 		"replication": strconv.FormatInt(int64(replication), 8)}
 
-	u, err := buildRequestUrl(fs.Config, &path, &params)
+	u, err := fs.requestUrl(ctx, &path, &params)
 	if err != nil {
 		return false, err
 	}
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
+	if _, isRemote := err.(RemoteException); isRemote {
+		return false, fs.erasureCodedFileError(ctx, OP_SETREPLICATION, path, err)
+	}
 	if err != nil {
 		return false, err
 	}
 
+	// the namenode ignores the replication of erasure-coded files.
+	if !hdfsData.Boolean {
+		if err := fs.erasureCodedFileError(ctx, OP_SETREPLICATION, path, nil); err != nil {
+			return false, err
+		}
+	}
+
 	return hdfsData.Boolean, nil
 }
 
 // Sets access or modification time for specified resource
 // See HDFS FileSystem.setTimes
 func (fs *FileSystem) SetTimes(path Path, accesstime int64, modificationtime int64) (bool, error) {
+	return fs.SetTimesContext(context.Background(), path, accesstime, modificationtime)
+}
+
+// SetTimes() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetTimesContext(ctx context.Context, path Path, accesstime int64, modificationtime int64) (bool, error) {
 	if path.Name == "" {
 		return false, fmt.Errorf("SetTimes() - Path cannot be empty.")
 	}
@@ -147,12 +196,12 @@ func (fs *FileSystem) SetTimes(path Path, accesstime int64, modificationtime int
 		"accesstime":       strconv.FormatInt(int64(accesstime), 10),
 		"modificationtime": strconv.FormatInt(int64(modificationtime), 10)}
 
-	u, err := buildRequestUrl(fs.Config, &path, &params)
+	u, err := fs.requestUrl(ctx, &path, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	rsp, err := fs.client.Do(req)
 	if err != nil {
 		return false, err
@@ -167,6 +216,11 @@ func (fs *FileSystem) SetTimes(path Path, accesstime int64, modificationtime int
 // Creates the specified directory(ies).
 // See HDFS FileSystem.mkdirs()
 func (fs *FileSystem) MkDirs(p Path, fm os.FileMode) (bool, error) {
+	return fs.MkDirsContext(context.Background(), p, fm)
+}
+
+// MkDirs() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) MkDirsContext(ctx context.Context, p Path, fm os.FileMode) (bool, error) {
 	params := map[string]string{"op": OP_MKDIRS}
 
 	if fm < 0 || fm > 1777 {
@@ -174,12 +228,12 @@ func (fs *FileSystem) MkDirs(p Path, fm os.FileMode) (bool, error) {
 	} else {
 		params["permission"] = strconv.FormatInt(int64(fm), 8)
 	}
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return false, err
@@ -195,6 +249,13 @@ func (fs *FileSystem) MkDirs(p Path, fm os.FileMode) (bool, error) {
 // createParent - when true, parent dirs are created if they don't exist
 // See http://hadoop.apache.org/docs/r2.2.0/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#HTTP_Query_Parameter_Dictionary
 func (fs *FileSystem) CreateSymlink(dest Path, link Path, createParent bool) (bool, error) {
+	return fs.CreateSymlinkContext(context.Background(), dest, link, createParent)
+}
+
+// CreateSymlink() with a context.Context to cancel the request or set a deadline.
+// Only link is resolved (see ResolvePath): a relative dest is kept as is,
+// it is resolved by HDFS against the parent of link when followed.
+func (fs *FileSystem) CreateSymlinkContext(ctx context.Context, dest Path, link Path, createParent bool) (bool, error) {
 	params := map[string]string{"op": OP_CREATESYMLINK}
 
 	if dest.Name == "" || link.Name == "" {
@@ -203,19 +264,17 @@ func (fs *FileSystem) CreateSymlink(dest Path, link Path, createParent bool) (bo
 
 	params["destination"] = dest.Name
 	params["createParent"] = strconv.FormatBool(createParent)
-	u, err := buildRequestUrl(fs.Config, &link, &params)
+	u, err := fs.requestUrl(ctx, &link, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	rsp, err := fs.client.Do(req)
-
-	defer rsp.Body.Close()
-
 	if err != nil {
 		return false, err
 	}
+	defer rsp.Body.Close()
 
 	return true, nil
 }
@@ -223,13 +282,18 @@ func (fs *FileSystem) CreateSymlink(dest Path, link Path, createParent bool) (bo
 // Returns status for a given file.  The Path must represent a FILE
 // on the remote system. (see HDFS FileSystem.getFileStatus())
 func (fs *FileSystem) GetFileStatus(p Path) (FileStatus, error) {
+	return fs.GetFileStatusContext(context.Background(), p)
+}
+
+// GetFileStatus() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetFileStatusContext(ctx context.Context, p Path) (FileStatus, error) {
 	params := map[string]string{"op": OP_GETFILESTATUS}
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return FileStatus{}, err
 	}
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return FileStatus{}, err
@@ -241,14 +305,19 @@ func (fs *FileSystem) GetFileStatus(p Path) (FileStatus, error) {
 // Returns an array of FileStatus for a given file directory.
 // For details, see HDFS FileSystem.listStatus()
 func (fs *FileSystem) ListStatus(p Path) ([]FileStatus, error) {
+	return fs.ListStatusContext(context.Background(), p)
+}
+
+// ListStatus() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) ListStatusContext(ctx context.Context, p Path) ([]FileStatus, error) {
 
 	params := map[string]string{"op": OP_LISTSTATUS}
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return nil, err
 	}
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return nil, err
@@ -260,13 +329,18 @@ func (fs *FileSystem) ListStatus(p Path) ([]FileStatus, error) {
 //Returns ContentSummary for the given path.
 //For detail, see HDFS FileSystem.getContentSummary()
 func (fs *FileSystem) GetContentSummary(p Path) (ContentSummary, error) {
+	return fs.GetContentSummaryContext(context.Background(), p)
+}
+
+// GetContentSummary() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetContentSummaryContext(ctx context.Context, p Path) (ContentSummary, error) {
 	params := map[string]string{"op": OP_GETCONTENTSUMMARY}
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return ContentSummary{}, err
 	}
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return ContentSummary{}, err
@@ -275,20 +349,76 @@ func (fs *FileSystem) GetContentSummary(p Path) (ContentSummary, error) {
 	return hdfsData.ContentSummary, nil
 }
 
+// Returns the home directory of the user.
+// See HDFS FileSystem.getHomeDirectory()
 func (fs *FileSystem) GetHomeDirectory() (Path, error) {
-	return Path{}, fmt.Errorf("Method GetHomeDirectory(), not implemented yet.")
+	return fs.GetHomeDirectoryContext(context.Background())
+}
+
+// GetHomeDirectory() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetHomeDirectoryContext(ctx context.Context) (Path, error) {
+	params := map[string]string{"op": OP_GETHOMEDIRECTORY}
+	u, err := buildRequestUrl(fs.Config, nil, &params)
+	if err != nil {
+		return Path{}, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return Path{}, err
+	}
+	if hdfsData.Path == "" {
+		return Path{}, fmt.Errorf("GetHomeDirectory() - server returned no home directory.")
+	}
+
+	return Path{Name: hdfsData.Path}, nil
+}
+
+// Resolves a relative path (not starting with /) against the home
+// directory of the user, like the Hadoop client does.  The home directory
+// is fetched once.  When Configuration.BasePath is set, relative paths are
+// left as they are and resolve against BasePath.
+func (fs *FileSystem) ResolvePath(p Path) (Path, error) {
+	return fs.ResolvePathContext(context.Background(), p)
+}
+
+// ResolvePath() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) ResolvePathContext(ctx context.Context, p Path) (Path, error) {
+	if p.Name == "" || strings.HasPrefix(p.Name, "/") || fs.Config.BasePath != "" || fs.home == nil {
+		return p, nil
+	}
+
+	fs.home.mu.Lock()
+	defer fs.home.mu.Unlock()
+	if fs.home.path == "" {
+		home, err := fs.GetHomeDirectoryContext(ctx)
+		if err != nil {
+			return Path{}, err
+		}
+		fs.home.path = home.Name
+	}
+
+	resolved := p
+	resolved.Name = path.Join(fs.home.path, p.Name)
+	return resolved, nil
 }
 
 // Returns HDFS file checksum.
 // For detail, see HDFS FileSystem.getFileChecksum()
 func (fs *FileSystem) GetFileChecksum(p Path) (FileChecksum, error) {
+	return fs.GetFileChecksumContext(context.Background(), p)
+}
+
+// GetFileChecksum() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetFileChecksumContext(ctx context.Context, p Path) (FileChecksum, error) {
 	params := map[string]string{"op": OP_GETFILECHECKSUM}
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return FileChecksum{}, err
 	}
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return FileChecksum{}, err
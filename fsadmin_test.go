@@ -1,11 +1,14 @@
 package gowfs
 
+import "context"
+import "errors"
 import "fmt"
 import "log"
 import "net/url"
 import "net/http"
 import "net/http/httptest"
 import "strconv"
+import "time"
 
 import "testing"
 
@@ -215,6 +218,9 @@ func Test_GetContentSummary(t *testing.T) {
 	if summary.SpaceConsumed != 24930 {
 		t.Errorf("GetContentSummary - not returning expected values <<%v>>", summary)
 	}
+	if archive := summary.TypeQuota[StorageTypeArchive]; archive.Consumed != 500 || archive.Quota != 10000 {
+		t.Errorf("GetContentSummary - not returning expected type quotas <<%v>>", summary.TypeQuota)
+	}
 }
 
 func Test_GetFileChecksum(t *testing.T) {
@@ -234,6 +240,46 @@ func Test_GetFileChecksum(t *testing.T) {
 	}
 }
 
+func Test_GetHomeDirectory(t *testing.T) {
+	server := mockServerFor_HomeDirectory(nil)
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+
+	home, err := fs.GetHomeDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if home.Name != "/user/hdfs" {
+		t.Errorf("Expecting home directory /user/hdfs, but got %s", home.Name)
+	}
+}
+
+func Test_ResolvePath(t *testing.T) {
+	var homeRequests int32
+	server := mockServerFor_HomeDirectory(&homeRequests)
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+
+	if _, err := fs.GetFileStatus(Path{Name: "data/file"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Rename(Path{Name: "data/file"}, Path{Name: "../hdfs/data/newfile"}); err != nil {
+		t.Fatal(err)
+	}
+	if homeRequests != 1 {
+		t.Errorf("Expecting the home directory to be fetched once, but got %d requests", homeRequests)
+	}
+
+	p, _ := fs.ResolvePath(Path{Name: "/tmp/file"})
+	if p.Name != "/tmp/file" {
+		t.Errorf("Expecting absolute path unchanged, but got %s", p.Name)
+	}
+}
+
 // *********************** Mock Servers ********************* //
 func mockServerFor_Rename() *httptest.Server {
 	handler := func(rsp http.ResponseWriter, req *http.Request) {
@@ -440,7 +486,7 @@ func mockServerFor_ListStatus() *httptest.Server {
 		if q.Get("op") != OP_LISTSTATUS {
 			panic(`Server Missing expected URL parameter: op=` + OP_LISTSTATUS)
 		}
-		fmt.Fprintln(rsp, listStatusRsp)
+		fmt.Fprint(rsp, listStatusRsp)
 	}
 	return httptest.NewServer(http.HandlerFunc(handler))
 }
@@ -472,7 +518,7 @@ func mockServerFor_FileStatus() *httptest.Server {
 		if q.Get("op") != OP_GETFILESTATUS {
 			panic(`Server Missing expected URL parameter: op=` + OP_GETFILESTATUS)
 		}
-		fmt.Fprintln(rsp, fileStatusRsp)
+		fmt.Fprint(rsp, fileStatusRsp)
 	}
 	return httptest.NewServer(http.HandlerFunc(handler))
 }
@@ -486,7 +532,8 @@ const contentSummaryRsp = `
     "length"        : 24930,
     "quota"         : -1,
     "spaceConsumed" : 24930,
-    "spaceQuota"    : -1
+    "spaceQuota"    : -1,
+    "typeQuota"     : { "ARCHIVE": { "consumed": 500, "quota": 10000 } }
   }
 }
 `
@@ -498,7 +545,7 @@ func mockServerFor_ContentSummary() *httptest.Server {
 		if q.Get("op") != OP_GETCONTENTSUMMARY {
 			panic(`Server Missing expected URL parameter: op=` + OP_GETCONTENTSUMMARY)
 		}
-		fmt.Fprintln(rsp, contentSummaryRsp)
+		fmt.Fprint(rsp, contentSummaryRsp)
 	}
 	return httptest.NewServer(http.HandlerFunc(handler))
 }
@@ -524,7 +571,56 @@ func mockServerFor_FileChecksum() *httptest.Server {
 		if q.Get("op") != OP_GETFILECHECKSUM {
 			panic(`Server Missing expected URL parameter: op=` + OP_GETFILECHECKSUM)
 		}
-		fmt.Fprintln(rsp, fileChecksumRsp)
+		fmt.Fprint(rsp, fileChecksumRsp)
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func Test_ListStatusContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		<-release
+		fmt.Fprint(rsp, listStatusRsp)
+	}))
+	defer server.Close()
+	defer close(release)
+	t.Logf("Started httptest.Server on %v", server.URL)
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := fs.ListStatusContext(ctx, Path{Name: "/test"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expecting context.DeadlineExceeded, but got %v", err)
+	}
+}
+
+// Answers GETHOMEDIRECTORY for user hdfs and expects other operations on
+// paths resolved against it.
+func mockServerFor_HomeDirectory(homeRequests *int32) *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		switch q.Get("op") {
+		case OP_GETHOMEDIRECTORY:
+			if homeRequests != nil {
+				*homeRequests++
+			}
+			fmt.Fprint(rsp, `{"Path": "/user/hdfs"}`)
+		case OP_GETFILESTATUS:
+			if req.URL.Path != "/webhdfs/v1/user/hdfs/data/file" {
+				log.Fatalf("Expecting path resolved against home, but got %v", req.URL.Path)
+			}
+			fmt.Fprint(rsp, fileStatusRsp)
+		case OP_RENAME:
+			if req.URL.Path != "/webhdfs/v1/user/hdfs/data/file" || q.Get("destination") != "/user/hdfs/data/newfile" {
+				log.Fatalf("Expecting paths resolved against home, but got [url=%v]", req.URL)
+			}
+			fmt.Fprint(rsp, `{"boolean": true}`)
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
 	}
 	return httptest.NewServer(http.HandlerFunc(handler))
 }
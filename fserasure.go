@@ -0,0 +1,130 @@
+package gowfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Enables the erasure coding policy name (i.e. RS-6-3-1024k) on the
+// cluster.  Requires superuser privilege.
+// See HDFS DistributedFileSystem.enableErasureCodingPolicy()
+func (fs *FileSystem) EnableErasureCodingPolicy(name string) (bool, error) {
+	return fs.EnableErasureCodingPolicyContext(context.Background(), name)
+}
+
+// EnableErasureCodingPolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) EnableErasureCodingPolicyContext(ctx context.Context, name string) (bool, error) {
+	if name == "" {
+		return false, fmt.Errorf("EnableErasureCodingPolicy() - param name cannot be empty.")
+	}
+	params := map[string]string{"op": OP_ENABLEECPOLICY, "ecpolicy": name}
+	return fs.putOperation(ctx, Path{Name: "/"}, params)
+}
+
+// Disables the erasure coding policy name on the cluster.  Requires
+// superuser privilege.
+// See HDFS DistributedFileSystem.disableErasureCodingPolicy()
+func (fs *FileSystem) DisableErasureCodingPolicy(name string) (bool, error) {
+	return fs.DisableErasureCodingPolicyContext(context.Background(), name)
+}
+
+// DisableErasureCodingPolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) DisableErasureCodingPolicyContext(ctx context.Context, name string) (bool, error) {
+	if name == "" {
+		return false, fmt.Errorf("DisableErasureCodingPolicy() - param name cannot be empty.")
+	}
+	params := map[string]string{"op": OP_DISABLEECPOLICY, "ecpolicy": name}
+	return fs.putOperation(ctx, Path{Name: "/"}, params)
+}
+
+// Sets the erasure coding policy name of the specified directory.  Only
+// files created afterwards are erasure-coded.
+// See HDFS DistributedFileSystem.setErasureCodingPolicy()
+func (fs *FileSystem) SetErasureCodingPolicy(p Path, name string) (bool, error) {
+	return fs.SetErasureCodingPolicyContext(context.Background(), p, name)
+}
+
+// SetErasureCodingPolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetErasureCodingPolicyContext(ctx context.Context, p Path, name string) (bool, error) {
+	if name == "" {
+		return false, fmt.Errorf("SetErasureCodingPolicy() - param name cannot be empty.")
+	}
+	params := map[string]string{"op": OP_SETECPOLICY, "ecpolicy": name}
+	return fs.putOperation(ctx, p, params)
+}
+
+// Returns the erasure coding policy of the specified path.  Name is empty
+// when the path is replicated.
+// See HDFS DistributedFileSystem.getErasureCodingPolicy()
+func (fs *FileSystem) GetErasureCodingPolicy(p Path) (ErasureCodingPolicy, error) {
+	return fs.GetErasureCodingPolicyContext(context.Background(), p)
+}
+
+// GetErasureCodingPolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetErasureCodingPolicyContext(ctx context.Context, p Path) (ErasureCodingPolicy, error) {
+	params := map[string]string{"op": OP_GETECPOLICY}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return ErasureCodingPolicy{}, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	rsp, err := fs.client.Do(req)
+	if err != nil {
+		return ErasureCodingPolicy{}, err
+	}
+	defer rsp.Body.Close()
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return ErasureCodingPolicy{}, err
+	}
+	// the policy is not wrapped in a named object.
+	if _, err := makeHdfsData(body); err != nil {
+		return ErasureCodingPolicy{}, err
+	}
+	var policy ErasureCodingPolicy
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &policy); err != nil {
+			return ErasureCodingPolicy{}, err
+		}
+	}
+	return policy, nil
+}
+
+// Removes the erasure coding policy of the specified directory, which
+// then inherits the policy of its parent.
+// See HDFS DistributedFileSystem.unsetErasureCodingPolicy()
+func (fs *FileSystem) UnsetErasureCodingPolicy(p Path) (bool, error) {
+	return fs.UnsetErasureCodingPolicyContext(context.Background(), p)
+}
+
+// UnsetErasureCodingPolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) UnsetErasureCodingPolicyContext(ctx context.Context, p Path) (bool, error) {
+	params := map[string]string{"op": OP_UNSETECPOLICY}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return false, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", u.String(), nil)
+	_, err = requestHdfsData(fs.client, *req)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Returns an ErasureCodedFileError wrapping err when the path is
+// erasure-coded, err otherwise.  The namenode exceptions do not tell the
+// striped files apart, their FileStatus does.
+func (fs *FileSystem) erasureCodedFileError(ctx context.Context, op string, p Path, err error) error {
+	stat, statErr := fs.GetFileStatusContext(ctx, p)
+	if statErr != nil || !stat.EcBit {
+		return err
+	}
+	return &ErasureCodedFileError{Op: op, Path: p, Policy: stat.EcPolicy, Err: err}
+}
@@ -0,0 +1,149 @@
+package gowfs
+
+import "fmt"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "strings"
+import "sync"
+import "testing"
+
+func Test_ErasureCodingPolicy(t *testing.T) {
+	server := mockServerFor_ErasureCoding(false)
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	p := Path{Name: "/warm"}
+
+	if _, err := fs.EnableErasureCodingPolicy("RS-6-3-1024k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.SetErasureCodingPolicy(p, "RS-6-3-1024k"); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := fs.GetErasureCodingPolicy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Name != "RS-6-3-1024k" || policy.NumDataUnits != 6 || policy.Schema.NumParityUnits != 3 || policy.CellSize != 1048576 {
+		t.Errorf("Expecting the RS-6-3-1024k policy, but got %v", policy)
+	}
+	stat, err := fs.GetFileStatus(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stat.EcBit || stat.EcPolicy != "RS-6-3-1024k" {
+		t.Errorf("Expecting an erasure-coded FileStatus, but got %v", stat)
+	}
+
+	if _, err := fs.UnsetErasureCodingPolicy(p); err != nil {
+		t.Fatal(err)
+	}
+	if policy, _ := fs.GetErasureCodingPolicy(p); policy.Name != "" {
+		t.Errorf("Expecting no policy, but got %v", policy)
+	}
+	if _, err := fs.DisableErasureCodingPolicy("RS-6-3-1024k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.DisableErasureCodingPolicy("XOR-2-1-1024k"); err == nil {
+		t.Error("Expecting an error for an unknown policy.")
+	}
+}
+
+func Test_SetReplication_ErasureCoded(t *testing.T) {
+	for _, reject := range []bool{false, true} {
+		server := mockServerFor_ErasureCoding(reject)
+
+		url, _ := url.Parse(server.URL)
+		fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+		fs.SetErasureCodingPolicy(Path{Name: "/warm"}, "RS-6-3-1024k")
+		ok, err := fs.SetReplication(Path{Name: "/warm"}, 3)
+		server.Close()
+
+		ecErr, isEcErr := err.(*ErasureCodedFileError)
+		if ok || !isEcErr {
+			t.Fatalf("Expecting ErasureCodedFileError (reject=%v), but got %v", reject, err)
+		}
+		if ecErr.Op != OP_SETREPLICATION || ecErr.Path.Name != "/warm" {
+			t.Errorf("Expecting SETREPLICATION on /warm, but got %v", ecErr)
+		}
+		if reject && ecErr.Unwrap() == nil {
+			t.Error("Expecting the RemoteException to be wrapped.")
+		}
+		if ecErr.Policy != "RS-6-3-1024k" {
+			t.Errorf("Expecting the policy name, but got %v", ecErr)
+		}
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// Cluster with the RS-6-3-1024k policy and a /warm file.  SETREPLICATION
+// on an erasure-coded /warm answers false, or an exception when reject.
+func mockServerFor_ErasureCoding(reject bool) *httptest.Server {
+	var mu sync.Mutex
+	policyJson := `{"cellSize": 1048576, "codecName": "rs", "id": 1, "name": "RS-6-3-1024k", "numDataUnits": 6, "numParityUnits": 3, "replicationPolicy": false,
+		"schema": {"codecName": "rs", "extraOptions": {}, "numDataUnits": 6, "numParityUnits": 3}, "systemPolicy": true}`
+	current := ""
+	remoteException := func(rsp http.ResponseWriter, exception, message string) {
+		rsp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(rsp, `{"RemoteException": {"exception": "%s", "javaClassName": "java.lang.%s", "message": "%s"}}`, exception, exception, message)
+	}
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := req.URL.Query()
+		p := strings.TrimPrefix(req.URL.Path, WebHdfsVer)
+		switch q.Get("op") {
+		case OP_ENABLEECPOLICY, OP_DISABLEECPOLICY:
+			if p != "/" || req.Method != "PUT" {
+				log.Fatalf("Unexpected request [url=%v]", req.URL)
+			}
+			if q.Get("ecpolicy") != "RS-6-3-1024k" {
+				remoteException(rsp, "HadoopIllegalArgumentException", "The policy name "+q.Get("ecpolicy")+" does not exist")
+			}
+			return
+		}
+		if p != "/warm" {
+			log.Fatalf("Unexpected path %v", p)
+		}
+		switch q.Get("op") {
+		case OP_SETECPOLICY:
+			if q.Get("ecpolicy") != "RS-6-3-1024k" || req.Method != "PUT" {
+				log.Fatalf("Unexpected request [url=%v]", req.URL)
+			}
+			current = q.Get("ecpolicy")
+		case OP_UNSETECPOLICY:
+			if req.Method != "POST" {
+				log.Fatalf("Unexpected method %v", req.Method)
+			}
+			current = ""
+		case OP_GETECPOLICY:
+			if current == "" {
+				fmt.Fprint(rsp, "null")
+				return
+			}
+			fmt.Fprint(rsp, policyJson)
+		case OP_GETFILESTATUS:
+			if current == "" {
+				fmt.Fprint(rsp, `{"FileStatus": {"pathSuffix": "", "replication": 3, "type": "FILE"}}`)
+				return
+			}
+			fmt.Fprintf(rsp, `{"FileStatus": {"ecBit": true, "ecPolicy": "%s", "pathSuffix": "", "replication": 0, "type": "FILE"}}`, current)
+		case OP_SETREPLICATION:
+			if current == "" {
+				fmt.Fprint(rsp, `{"boolean": true}`)
+			} else if reject {
+				remoteException(rsp, "IllegalArgumentException", "Cannot set replication to a file with striped blocks")
+			} else {
+				fmt.Fprint(rsp, `{"boolean": false}`)
+			}
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
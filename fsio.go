@@ -1,6 +1,7 @@
 package gowfs
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Creates a new file and stores its content in HDFS.
@@ -23,6 +25,21 @@ func (fs *FileSystem) Create(
 	permission os.FileMode,
 	buffersize uint,
 	contenttype string) (bool, error) {
+	return fs.CreateContext(context.Background(), data, p, overwrite, blocksize, replication, permission, buffersize, contenttype)
+}
+
+// Create() with a context.Context to cancel the request or set a deadline.
+// If ctx ends while data is sent to the datanode, the remote file may be
+// left partially written and an IncompleteWriteError is returned.
+func (fs *FileSystem) CreateContext(ctx context.Context,
+	data io.Reader,
+	p Path,
+	overwrite bool,
+	blocksize uint64,
+	replication uint16,
+	permission os.FileMode,
+	buffersize uint,
+	contenttype string) (bool, error) {
 
 	params := map[string]string{"op": OP_CREATE}
 	params["overwrite"] = strconv.FormatBool(overwrite)
@@ -51,45 +68,19 @@ func (fs *FileSystem) Create(
 		params["buffersize"] = strconv.FormatInt(int64(buffersize), 10)
 	}
 
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return false, err
 	}
 
-	// take over default transport to avoid redirect
-	req, _ := http.NewRequest("PUT", u.String(), nil)
-	rsp, err := fs.transport.RoundTrip(req)
-	if err != nil {
-		return false, err
-	}
-
-	// extract returned url in header.
-	loc := rsp.Header.Get("Location")
-	u, err = url.ParseRequestURI(loc)
-	if err != nil {
-		return false, fmt.Errorf("FileSystem.Create(%s) - invalid redirect URL from server: %s", u, err.Error())
-	}
-
-	req, _ = http.NewRequest("PUT", u.String(), data)
-	// set content type
-	if contenttype != "" {
-		req.Header.Set("Content-Type", contenttype)
-	}
-	rsp, err = fs.client.Do(req)
+	idempotent := overwrite // a non-overwrite create fails if retried after success
+	err = fs.retryWrite(ctx, OP_CREATE, idempotent, data, func(data io.Reader) (int, error) {
+		return fs.redirectedWrite(ctx, "PUT", u, p, data, contenttype, http.StatusCreated, "FileSystem.Create")
+	})
 	if err != nil {
-		fmt.Errorf("FileSystem.Create(%s) - bad url: %s", loc, err.Error())
 		return false, err
 	}
 
-	if rsp.StatusCode != http.StatusCreated {
-		defer rsp.Body.Close()
-		_, err = responseToHdfsData(rsp)
-		if err != nil {
-			return false, err
-		}
-		return false, fmt.Errorf("FileSystem.Create(%s) - File not created.  Server returned status %v", loc, rsp.StatusCode)
-	}
-
 	return true, nil
 }
 
@@ -97,6 +88,11 @@ func (fs *FileSystem) Create(
 //See HDFS WebHdfsFileSystem.open()
 // See http://hadoop.apache.org/docs/r2.2.0/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#HTTP_Query_Parameter_Dictionary
 func (fs *FileSystem) Open(p Path, offset, length int64, buffSize int) (io.ReadCloser, error) {
+	return fs.OpenContext(context.Background(), p, offset, length, buffSize)
+}
+
+// Open() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) OpenContext(ctx context.Context, p Path, offset, length int64, buffSize int) (io.ReadCloser, error) {
 	params := map[string]string{"op": OP_OPEN}
 
 	if offset < 0 {
@@ -115,12 +111,21 @@ func (fs *FileSystem) Open(p Path, offset, length int64, buffSize int) (io.ReadC
 		params["buffersize"] = strconv.Itoa(buffSize)
 	}
 
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return nil, err
 	}
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	// locate the datanode first, the client then sends no redirected request.
+	if fs.Config.NoRedirect && !fs.isHttpFS() {
+		u, _, err = fs.locateDataNode(ctx, "GET", withNoRedirect(u), p, "Open")
+		if err != nil {
+			return nil, err
+		}
+		fs.prepareDatanodeUrl(u)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	rsp, err := fs.client.Do(req)
 	if err != nil {
 		return nil, err
@@ -145,6 +150,13 @@ func (fs *FileSystem) Open(p Path, offset, length int64, buffSize int) (io.ReadC
 // See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#Append_to_a_File
 // NOTE: Append() is known to have issues - see https://issues.apache.org/jira/browse/HDFS-4600
 func (fs *FileSystem) Append(data io.Reader, p Path, buffersize int, contenttype string) (bool, error) {
+	return fs.AppendContext(context.Background(), data, p, buffersize, contenttype)
+}
+
+// Append() with a context.Context to cancel the request or set a deadline.
+// If ctx ends while data is sent to the datanode, part of the data may
+// have been appended and an IncompleteWriteError is returned.
+func (fs *FileSystem) AppendContext(ctx context.Context, data io.Reader, p Path, buffersize int, contenttype string) (bool, error) {
 	params := map[string]string{"op": OP_APPEND}
 
 	if buffersize == 0 {
@@ -153,73 +165,283 @@ func (fs *FileSystem) Append(data io.Reader, p Path, buffersize int, contenttype
 		params["buffersize"] = strconv.FormatInt(int64(buffersize), 10)
 	}
 
-	u, err := buildRequestUrl(fs.Config, &p, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return false, err
 	}
 
-	// take over default transport to avoid redirect
-	req, _ := http.NewRequest("POST", u.String(), nil)
-	rsp, err := fs.transport.RoundTrip(req)
+	err = fs.retryWrite(ctx, OP_APPEND, false, data, func(data io.Reader) (int, error) {
+		return fs.redirectedWrite(ctx, "POST", u, p, data, contenttype, http.StatusOK, "Append")
+	})
 	if err != nil {
 		return false, err
 	}
 
-	// extract returned url in header.
-	loc := rsp.Header.Get("Location")
-	u, err = url.ParseRequestURI(loc)
-	if err != nil {
-		return false, fmt.Errorf("Append(%s) - did not receive a valid URL from server.", loc)
-	}
+	return true, nil
+}
 
-	req, _ = http.NewRequest("POST", u.String(), data)
-	// set content type
-	if contenttype != "" {
-		req.Header.Set("Content-Type", contenttype)
+// Concatenate (on the server) a list of given files paths to a new file.
+// Relative sources are resolved like target (see ResolvePath).
+// See HDFS FileSystem.concat()
+func (fs *FileSystem) Concat(target Path, sources []string) (bool, error) {
+	return fs.ConcatContext(context.Background(), target, sources)
+}
+
+// Concat() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) ConcatContext(ctx context.Context, target Path, sources []string) (bool, error) {
+	if (target == Path{}) {
+		return false, fmt.Errorf("Concat() - The target path must be provided.")
 	}
-	rsp, err = fs.client.Do(req)
+	resolved := make([]string, len(sources))
+	for i, source := range sources {
+		p, err := fs.ResolvePathContext(ctx, Path{Name: source})
+		if err != nil {
+			return false, err
+		}
+		resolved[i] = p.Name
+	}
+	params := map[string]string{"op": OP_CONCAT}
+	params["sources"] = strings.Join(resolved, ",")
+
+	u, err := fs.requestUrl(ctx, &target, &params)
 	if err != nil {
 		return false, err
 	}
 
+	req, _ := http.NewRequestWithContext(ctx, "POST", u.String(), nil)
+	rsp, err := fs.client.Do(req)
+	if err != nil {
+		return false, err
+	}
 	if rsp.StatusCode != http.StatusOK {
 		defer rsp.Body.Close()
 		_, err = responseToHdfsData(rsp)
 		if err != nil {
 			return false, err
 		}
-		return false, fmt.Errorf("Append(%s) - File not created.  Server returned status %v", loc, rsp.StatusCode)
+		return false, fmt.Errorf("Concat(%s) - File not concatenated.  Server returned status %v", u.String(), rsp.StatusCode)
 	}
-
 	return true, nil
 }
 
-// Concatenate (on the server) a list of given files paths to a new file.
-// See HDFS FileSystem.concat()
-func (fs *FileSystem) Concat(target Path, sources []string) (bool, error) {
-	if (target == Path{}) {
-		return false, fmt.Errorf("Concat() - The target path must be provided.")
+// Truncates the file to newLength bytes.  Returns true when the file was
+// truncated immediately, false when the last block must first be recovered
+// (see WaitForTruncate), during which the file can't be written.
+// See HDFS FileSystem.truncate()
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#Truncate_a_File
+func (fs *FileSystem) Truncate(p Path, newLength int64) (bool, error) {
+	return fs.TruncateContext(context.Background(), p, newLength)
+}
+
+// Truncate() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) TruncateContext(ctx context.Context, p Path, newLength int64) (bool, error) {
+	if newLength < 0 {
+		return false, fmt.Errorf("Truncate() - newLength must not be negative.")
 	}
-	params := map[string]string{"op": OP_CONCAT}
-	params["sources"] = strings.Join(sources, ",")
+	params := map[string]string{"op": OP_TRUNCATE}
+	params["newlength"] = strconv.FormatInt(newLength, 10)
 
-	u, err := buildRequestUrl(fs.Config, &target, &params)
+	u, err := fs.requestUrl(ctx, &p, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("POST", u.String(), nil)
-	rsp, err := fs.client.Do(req)
+	req, _ := http.NewRequestWithContext(ctx, "POST", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return false, err
 	}
-	if rsp.StatusCode != http.StatusOK {
-		defer rsp.Body.Close()
+
+	return hdfsData.Boolean, nil
+}
+
+// Waits until a truncate that needs block recovery has completed, polling
+// the file status every interval until its length is newLength.  Use
+// WaitForTruncateContext to bound the wait.
+func (fs *FileSystem) WaitForTruncate(p Path, newLength int64, interval time.Duration) error {
+	return fs.WaitForTruncateContext(context.Background(), p, newLength, interval)
+}
+
+// WaitForTruncate() with a context.Context to cancel the wait or set a deadline.
+func (fs *FileSystem) WaitForTruncateContext(ctx context.Context, p Path, newLength int64, interval time.Duration) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		stat, err := fs.GetFileStatusContext(ctx, p)
+		if err != nil {
+			return err
+		}
+		if stat.Length == newLength {
+			return nil
+		}
+		if stat.Length < newLength {
+			return fmt.Errorf("WaitForTruncate(%s) - file length %d is below %d.", p.Name, stat.Length, newLength)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Returns the datanode URL the namenode assigns to op (OP_OPEN,
+// OP_CREATE or OP_APPEND) on the specified path, using default
+// parameters.  The transfer can then be done by another process.
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#Redirect_or_Not
+func (fs *FileSystem) DataNodeLocation(p Path, op string) (*url.URL, error) {
+	return fs.DataNodeLocationContext(context.Background(), p, op)
+}
+
+// DataNodeLocation() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) DataNodeLocationContext(ctx context.Context, p Path, op string) (*url.URL, error) {
+	var method string
+	switch op {
+	case OP_OPEN:
+		method = "GET"
+	case OP_CREATE:
+		method = "PUT"
+	case OP_APPEND:
+		method = "POST"
+	default:
+		return nil, fmt.Errorf("DataNodeLocation() - op %s is not sent to datanodes.", op)
+	}
+	params := map[string]string{"op": op, "noredirect": "true"}
+
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	dnUrl, _, err := fs.locateDataNode(ctx, method, u, p, "DataNodeLocation")
+	if err != nil {
+		return nil, err
+	}
+	fs.prepareDatanodeUrl(dnUrl)
+	// the URL is returned, it is not dialed by the built-in transport.
+	if fs.Config.Transport == nil {
+		dnUrl.Host = fs.Config.dataNodeAddr(dnUrl.Host)
+	}
+	return dnUrl, nil
+}
+
+// Sends a two-step write: the namenode request u is answered with a
+// redirect to a datanode, then data is sent to the datanode.  HttpFS
+// servers receive data in a single request instead.  On failure, the
+// status code of the failed response (if any) is returned with the error.
+func (fs *FileSystem) redirectedWrite(
+	ctx context.Context,
+	method string,
+	u *url.URL,
+	p Path,
+	data io.Reader,
+	contenttype string,
+	expected int,
+	name string) (int, error) {
+
+	op := u.Query().Get("op")
+	if fs.isHttpFS() {
+		return fs.sendData(ctx, method, httpFSUploadUrl(u), p, op, data, HttpFSContentType, expected, name)
+	}
+
+	if fs.Config.NoRedirect {
+		u = withNoRedirect(u)
+	}
+	dnUrl, status, err := fs.locateDataNode(ctx, method, u, p, name)
+	if err != nil {
+		return status, err
+	}
+	if isHttpFSUploadUrl(dnUrl) {
+		fs.detectedHttpFS()
+		contenttype = HttpFSContentType
+	}
+	fs.prepareDatanodeUrl(dnUrl)
+
+	return fs.sendData(ctx, method, dnUrl, p, op, data, contenttype, expected, name)
+}
+
+// Sends request u to the namenode and returns the datanode URL it points
+// to, from the Location header of a redirect or, with noredirect=true,
+// from the Location of the JSON body.  On failure, the status code of
+// the namenode response (if any) is returned with the error.
+func (fs *FileSystem) locateDataNode(ctx context.Context, method string, u *url.URL, p Path, name string) (*url.URL, int, error) {
+	// take over default transport to avoid redirect
+	req, _ := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	rsp, err := fs.roundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rsp.Body.Close()
+
+	// extract returned url in header, or in body with noredirect=true.
+	loc := rsp.Header.Get("Location")
+	if loc == "" {
+		hdfsData, err := responseToHdfsData(rsp)
+		if err != nil && rsp.StatusCode >= 400 {
+			return nil, rsp.StatusCode, err
+		}
+		loc = hdfsData.Location
+	}
+	if loc == "" && rsp.StatusCode < 300 {
+		return nil, 0, fmt.Errorf("%s(%s) - server did not redirect the request.  Set Configuration.Flavor to FlavorHttpFS for HttpFS servers.", name, p.Name)
+	}
+	// a gateway (i.e. Knox) may redirect to a path relative to itself.
+	dnUrl, err := url.ParseRequestURI(loc)
+	if err == nil && !dnUrl.IsAbs() {
+		dnUrl = req.URL.ResolveReference(dnUrl)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s(%s) - invalid redirect URL from server: %s", name, p.Name, err.Error())
+	}
+	return dnUrl, 0, nil
+}
+
+// Returns u with noredirect=true.
+func withNoRedirect(u *url.URL) *url.URL {
+	nu := *u
+	q := nu.Query()
+	q.Set("noredirect", "true")
+	nu.RawQuery = q.Encode()
+	return &nu
+}
+
+// Sends data to u, the datanode (or HttpFS) URL of a write.
+func (fs *FileSystem) sendData(
+	ctx context.Context,
+	method string,
+	u *url.URL,
+	p Path,
+	op string,
+	data io.Reader,
+	contenttype string,
+	expected int,
+	name string) (int, error) {
+
+	req, _ := http.NewRequestWithContext(ctx, method, u.String(), data)
+	// set content type
+	if contenttype != "" {
+		req.Header.Set("Content-Type", contenttype)
+	}
+	rsp, err := fs.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, &IncompleteWriteError{Op: op, Path: p, Err: ctx.Err()}
+		}
+		return 0, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != expected {
 		_, err = responseToHdfsData(rsp)
 		if err != nil {
-			return false, err
+			return rsp.StatusCode, err
 		}
-		return false, fmt.Errorf("Concat(%s) - File not concatenated.  Server returned status %v", u.String(), rsp.StatusCode)
+		return rsp.StatusCode, fmt.Errorf("%s(%s) - Server returned status %v", name, u.String(), rsp.StatusCode)
 	}
-	return true, nil
+
+	return 0, nil
 }
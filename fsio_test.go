@@ -2,6 +2,9 @@ package gowfs
 
 import "testing"
 
+import "context"
+import "errors"
+import "io"
 import "net/url"
 import "fmt"
 import "log"
@@ -10,6 +13,7 @@ import "io/ioutil"
 import "net/http"
 import "strings"
 import "net/http/httptest"
+import "time"
 
 func Test_Create(t *testing.T) {
 	// start a new server to handle redirect
@@ -99,7 +103,7 @@ func Test_Append(t *testing.T) {
 	fs, _ := NewFileSystem(conf)
 
 	ok, err := fs.Append(bytes.NewBufferString("Hello webhdfs users!"),
-		Path{Name: "/testing/existing.f"}, 4096)
+		Path{Name: "/testing/existing.f"}, 4096, "")
 
 	if err != nil {
 		t.Fatal(err)
@@ -127,6 +131,215 @@ func Test_Concat(t *testing.T) {
 
 }
 
+func Test_CreateContext_Cancel(t *testing.T) {
+	received := make(chan struct{})
+	server1 := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		buf := make([]byte, 5)
+		io.ReadFull(req.Body, buf)
+		close(received)
+		ioutil.ReadAll(req.Body) // until client gives up
+	}))
+	defer server1.Close()
+	servUrl, _ := url.Parse(server1.URL)
+	server2 := mockServerFor_CreatFile(servUrl)
+	defer server2.Close()
+
+	url, _ := url.Parse(server2.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	data, writer := io.Pipe()
+	defer writer.Close()
+	go func() {
+		writer.Write([]byte("Hello"))
+		<-received
+		cancel()
+		writer.Close()
+	}()
+
+	_, err := fs.CreateContext(ctx, data, Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	incomplete, ok := err.(*IncompleteWriteError)
+	if !ok {
+		t.Fatalf("Expecting IncompleteWriteError, but got %v", err)
+	}
+	if incomplete.Path.Name != "/testing/newfile" || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expecting cancelled write of /testing/newfile, but got %v", err)
+	}
+}
+
+func Test_DataNodeAddrs(t *testing.T) {
+	datanode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		// only the connection is rewritten.
+		if req.Host != "datanode.cluster.internal:50075" {
+			log.Fatalf("Expecting Host datanode.cluster.internal:50075, but got %v", req.Host)
+		}
+		switch req.URL.Query().Get("op") {
+		case OP_CREATE:
+			rsp.WriteHeader(http.StatusCreated)
+		case OP_OPEN:
+			fmt.Fprint(rsp, "Hello webhdfs users!")
+		default:
+			log.Fatalf("Unexpected datanode request [url=%v]", req.URL)
+		}
+	}))
+	defer datanode.Close()
+	// redirects point at a datanode name the client can't resolve.
+	namenode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.Header().Set("Location", "http://datanode.cluster.internal:50075"+req.URL.RequestURI())
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer namenode.Close()
+
+	nnUrl, _ := url.Parse(namenode.URL)
+	dnUrl, _ := url.Parse(datanode.URL)
+	conf := Configuration{
+		Addr:          nnUrl.Host,
+		User:          "hdfs",
+		DataNodeAddrs: map[string]string{"datanode.cluster.internal:50075": dnUrl.Host},
+	}
+	fs, _ := NewFileSystem(conf)
+
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := fs.Open(Path{Name: "/testing/newfile"}, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, _ := ioutil.ReadAll(reader)
+	if string(data) != "Hello webhdfs users!" {
+		t.Errorf("Expecting data from the rewritten datanode, but got %v", string(data))
+	}
+}
+
+func Test_DataNodeResolver(t *testing.T) {
+	datanode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer datanode.Close()
+	namenode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.Header().Set("Location", "http://datanode.cluster.internal:50075"+req.URL.RequestURI())
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer namenode.Close()
+
+	nnUrl, _ := url.Parse(namenode.URL)
+	dnUrl, _ := url.Parse(datanode.URL)
+	var resolved []string
+	conf := Configuration{
+		Addr: nnUrl.Host,
+		User: "hdfs",
+		DataNodeResolver: func(addr string) string {
+			resolved = append(resolved, addr)
+			return dnUrl.Host
+		},
+	}
+	fs, _ := NewFileSystem(conf)
+
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0] != "datanode.cluster.internal:50075" {
+		t.Errorf("Expecting the datanode address to be resolved once, but got %v", resolved)
+	}
+}
+
+func Test_NoRedirect(t *testing.T) {
+	datanode := mockServerFor_NoRedirectDatanode()
+	defer datanode.Close()
+	namenode := mockServerFor_NoRedirect(datanode.URL)
+	defer namenode.Close()
+
+	u, _ := url.Parse(namenode.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, User: "hdfs", NoRedirect: true})
+
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fs.Append(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := fs.Open(Path{Name: "/testing/newfile"}, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, _ := ioutil.ReadAll(reader)
+	if string(data) != "Hello webhdfs users!" {
+		t.Errorf("Expecting data from the datanode, but got %v", string(data))
+	}
+}
+
+func Test_DataNodeLocation(t *testing.T) {
+	namenode := mockServerFor_NoRedirect("http://datanode:50075")
+	defer namenode.Close()
+
+	u, _ := url.Parse(namenode.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, User: "hdfs"})
+
+	loc, err := fs.DataNodeLocation(Path{Name: "/testing/newfile"}, OP_OPEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Host != "datanode:50075" || loc.Query().Get("op") != OP_OPEN {
+		t.Errorf("Expecting the OPEN location on datanode:50075, but got %v", loc)
+	}
+
+	if _, err := fs.DataNodeLocation(Path{Name: "/testing/newfile"}, OP_LISTSTATUS); err == nil {
+		t.Error("Expecting an error for an op not sent to datanodes.")
+	}
+
+	// the returned location is not dialed by the FileSystem.
+	fs, _ = NewFileSystem(Configuration{Addr: u.Host, User: "hdfs", DataNodeAddrs: map[string]string{"datanode": "localhost"}})
+	if loc, _ := fs.DataNodeLocation(Path{Name: "/testing/newfile"}, OP_OPEN); loc == nil || loc.Host != "localhost:50075" {
+		t.Errorf("Expecting the location rewritten to localhost:50075, but got %v", loc)
+	}
+}
+
+func Test_Truncate(t *testing.T) {
+	server := mockServerFor_Truncate()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+
+	done, err := fs.Truncate(Path{Name: "/logs/batch.log"}, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Fatal("Expecting truncate to need block recovery.")
+	}
+	if err := fs.WaitForTruncate(Path{Name: "/logs/batch.log"}, 512, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fs.Truncate(Path{Name: "/logs/batch.log"}, -1); err == nil {
+		t.Error("Expecting an error for a negative length.")
+	}
+}
+
+func Test_WaitForTruncateContext(t *testing.T) {
+	server := mockServerFor_Truncate()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+
+	// the length never reaches 100, the wait ends with ctx.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := fs.WaitForTruncateContext(ctx, Path{Name: "/logs/batch.log"}, 100, time.Millisecond)
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expecting context.DeadlineExceeded, but got %v", err)
+	}
+}
+
 // ***************************** Mock Servers for Tests **********************//
 
 type code int
@@ -281,6 +494,10 @@ func mockServerFor_Append() *httptest.Server {
 
 func mockServerFor_Concat() *httptest.Server {
 	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("op") == OP_GETHOMEDIRECTORY {
+			fmt.Fprint(rsp, `{"Path": "/user/hdfs"}`)
+			return
+		}
 		if req.Method != "POST" {
 			log.Fatalf("Expecting Request.Method POST, but got %v", req.Method)
 		}
@@ -289,8 +506,9 @@ func mockServerFor_Concat() *httptest.Server {
 		if q.Get("op") != OP_CONCAT {
 			log.Fatalf("Server Missing expected URL parameter: op=%v", OP_CONCAT)
 		}
-		if q.Get("sources") != strings.Join([]string{"a/b/c", "e/f/g"}, ",") {
-			log.Fatalf("Expected param sources a/b/c, e/f/g, but was %v", q.Get("sources"))
+		// relative sources are resolved against the home directory.
+		if q.Get("sources") != strings.Join([]string{"/user/hdfs/a/b/c", "/user/hdfs/e/f/g"}, ",") {
+			log.Fatalf("Expected param sources /user/hdfs/a/b/c, /user/hdfs/e/f/g, but was %v", q.Get("sources"))
 		}
 
 		fmt.Fprintf(rsp, "")
@@ -298,3 +516,61 @@ func mockServerFor_Concat() *httptest.Server {
 
 	return httptest.NewServer(http.HandlerFunc(handler))
 }
+
+// Namenode answering noredirect=true requests with the datanode location
+// in the JSON body.
+func mockServerFor_NoRedirect(datanodeUrl string) *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		if q.Get("noredirect") != "true" {
+			log.Fatalf("Expecting param noredirect=true, but got [url=%v]", req.URL)
+		}
+		q.Del("noredirect")
+		fmt.Fprintf(rsp, `{"Location":"%s%s?%s"}`, datanodeUrl, req.URL.Path, q.Encode())
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func mockServerFor_NoRedirectDatanode() *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		switch req.URL.Query().Get("op") {
+		case OP_CREATE:
+			rsp.WriteHeader(http.StatusCreated)
+		case OP_APPEND:
+			rsp.WriteHeader(http.StatusOK)
+		case OP_OPEN:
+			fmt.Fprint(rsp, "Hello webhdfs users!")
+		default:
+			log.Fatalf("Unexpected datanode request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+// Truncate needing recovery: the file length only reaches 512 bytes on
+// the third status request.
+func mockServerFor_Truncate() *httptest.Server {
+	lengths := []int64{2048, 1024, 512}
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		switch q.Get("op") {
+		case OP_TRUNCATE:
+			if req.Method != "POST" {
+				log.Fatalf("Expecting Request.Method POST, but got %v", req.Method)
+			}
+			if q.Get("newlength") != "512" {
+				log.Fatalf("Expecting param newlength=512, but got %v", q.Get("newlength"))
+			}
+			fmt.Fprint(rsp, `{"boolean": false}`)
+		case OP_GETFILESTATUS:
+			length := lengths[0]
+			if len(lengths) > 1 {
+				lengths = lengths[1:]
+			}
+			fmt.Fprintf(rsp, `{"FileStatus": {"length": %d, "type": "FILE"}}`, length)
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
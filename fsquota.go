@@ -0,0 +1,87 @@
+package gowfs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Special quota values for SetQuota() and SetQuotaByStorageType().
+const (
+	QuotaReset   int64 = -1            // removes the quota
+	QuotaDontSet int64 = math.MaxInt64 // keeps the current quota
+)
+
+// Returns the quotas of the specified directory and their usage.  It is
+// cheaper than GetContentSummary() on large trees.
+// See HDFS FileSystem.getQuotaUsage()
+func (fs *FileSystem) GetQuotaUsage(p Path) (QuotaUsage, error) {
+	return fs.GetQuotaUsageContext(context.Background(), p)
+}
+
+// GetQuotaUsage() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetQuotaUsageContext(ctx context.Context, p Path) (QuotaUsage, error) {
+	params := map[string]string{"op": OP_GETQUOTAUSAGE}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return QuotaUsage{}, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return QuotaUsage{}, err
+	}
+
+	return hdfsData.QuotaUsage, nil
+}
+
+// Sets the namespace quota (number of files and directories) and the
+// storage space quota (bytes, replicas included) of the specified
+// directory.  Use QuotaReset to remove a quota and QuotaDontSet to keep it.
+// Requires superuser privilege.
+// See HDFS DistributedFileSystem.setQuota()
+func (fs *FileSystem) SetQuota(p Path, nsQuota, ssQuota int64) (bool, error) {
+	return fs.SetQuotaContext(context.Background(), p, nsQuota, ssQuota)
+}
+
+// SetQuota() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetQuotaContext(ctx context.Context, p Path, nsQuota, ssQuota int64) (bool, error) {
+	if !validQuota(nsQuota) || !validQuota(ssQuota) {
+		return false, fmt.Errorf("SetQuota() - quotas must be positive, QuotaReset or QuotaDontSet.")
+	}
+	params := map[string]string{
+		"op":                OP_SETQUOTA,
+		"namespacequota":    strconv.FormatInt(nsQuota, 10),
+		"storagespacequota": strconv.FormatInt(ssQuota, 10)}
+	return fs.putOperation(ctx, p, params)
+}
+
+// Sets the storage space quota of the specified directory for a storage
+// type (i.e. StorageTypeSSD).  Use QuotaReset to remove the quota.
+// Requires superuser privilege.
+// See HDFS DistributedFileSystem.setQuotaByStorageType()
+func (fs *FileSystem) SetQuotaByStorageType(p Path, storageType string, quota int64) (bool, error) {
+	return fs.SetQuotaByStorageTypeContext(context.Background(), p, storageType, quota)
+}
+
+// SetQuotaByStorageType() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetQuotaByStorageTypeContext(ctx context.Context, p Path, storageType string, quota int64) (bool, error) {
+	if storageType == "" {
+		return false, fmt.Errorf("SetQuotaByStorageType() - param storageType cannot be empty.")
+	}
+	if !validQuota(quota) {
+		return false, fmt.Errorf("SetQuotaByStorageType() - quota must be positive, QuotaReset or QuotaDontSet.")
+	}
+	params := map[string]string{
+		"op":                OP_SETQUOTABYSTORAGETYPE,
+		"storagetype":       storageType,
+		"storagespacequota": strconv.FormatInt(quota, 10)}
+	return fs.putOperation(ctx, p, params)
+}
+
+func validQuota(quota int64) bool {
+	return quota > 0 || quota == QuotaReset
+}
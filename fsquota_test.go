@@ -0,0 +1,135 @@
+package gowfs
+
+import "fmt"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "path"
+import "strconv"
+import "strings"
+import "sync"
+import "testing"
+
+func Test_Quota(t *testing.T) {
+	server := mockServerFor_Quota()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	p := Path{Name: "/projects/p1"}
+
+	if _, err := fs.SetQuota(p, 1000, 1<<30); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.SetQuotaByStorageType(p, StorageTypeSSD, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := fs.GetQuotaUsage(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Quota != 1000 || usage.SpaceQuota != 1<<30 || usage.FileAndDirectoryCount != 1 {
+		t.Errorf("Expecting the quotas set, but got %v", usage)
+	}
+	if ssd := usage.TypeQuota[StorageTypeSSD]; ssd.Quota != 1<<20 || ssd.Consumed != 0 {
+		t.Errorf("Expecting an SSD quota of 1MB, but got %v", usage.TypeQuota)
+	}
+
+	if _, err := fs.SetQuota(p, QuotaDontSet, QuotaReset); err != nil {
+		t.Fatal(err)
+	}
+	usage, _ = fs.GetQuotaUsage(p)
+	if usage.Quota != 1000 || usage.SpaceQuota != -1 {
+		t.Errorf("Expecting the namespace quota kept and space quota reset, but got %v", usage)
+	}
+
+	if _, err := fs.SetQuota(p, 0, 10); err == nil {
+		t.Error("Expecting an error for a zero quota.")
+	}
+	if _, err := fs.SetQuotaByStorageType(p, "", 10); err == nil {
+		t.Error("Expecting an error for an empty storage type.")
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// Quotas of the directories under /projects, created with MKDIRS.
+// Negative quotas (other than -1) are rejected like on a namenode.
+func mockServerFor_Quota() *httptest.Server {
+	type quota struct {
+		ns, ss int64
+		types  map[string]int64
+	}
+	var mu sync.Mutex
+	dirs := map[string]*quota{
+		"/projects":    {ns: -1, ss: -1, types: map[string]int64{}},
+		"/projects/p1": {ns: -1, ss: -1, types: map[string]int64{}},
+	}
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := req.URL.Query()
+		p := strings.TrimPrefix(req.URL.Path, WebHdfsVer)
+		if p != "/projects" && !strings.HasPrefix(p, "/projects/") {
+			log.Fatalf("Unexpected path %v", p)
+		}
+		dir, ok := dirs[p]
+		if !ok && q.Get("op") != OP_MKDIRS {
+			rsp.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(rsp, `{"RemoteException": {"exception": "FileNotFoundException", "javaClassName": "java.io.FileNotFoundException", "message": "File does not exist: %s"}}`, p)
+			return
+		}
+		parse := func(name string) int64 {
+			v, err := strconv.ParseInt(q.Get(name), 10, 64)
+			if err != nil {
+				log.Fatalf("Invalid %s [url=%v]", name, req.URL)
+			}
+			return v
+		}
+		switch q.Get("op") {
+		case OP_GETFILESTATUS:
+			fmt.Fprint(rsp, `{"FileStatus": {"pathSuffix": "", "type": "DIRECTORY"}}`)
+		case OP_MKDIRS:
+			for d := p; dirs[d] == nil; d = path.Dir(d) {
+				dirs[d] = &quota{ns: -1, ss: -1, types: map[string]int64{}}
+			}
+			fmt.Fprint(rsp, `{"boolean": true}`)
+		case OP_DELETE:
+			for d := range dirs {
+				if strings.HasPrefix(d, p+"/") && q.Get("recursive") != "true" {
+					rsp.WriteHeader(http.StatusForbidden)
+					fmt.Fprintf(rsp, `{"RemoteException": {"exception": "PathIsNotEmptyDirectoryException", "javaClassName": "org.apache.hadoop.fs.PathIsNotEmptyDirectoryException", "message": "%s is non empty: Directory is not empty"}}`, p)
+					return
+				}
+			}
+			delete(dirs, p)
+			fmt.Fprint(rsp, `{"boolean": true}`)
+		case OP_SETQUOTA:
+			ns, ss := parse("namespacequota"), parse("storagespacequota")
+			if ns > 1<<40 && ns != QuotaDontSet {
+				rsp.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(rsp, `{"RemoteException": {"exception": "AccessControlException", "javaClassName": "org.apache.hadoop.security.AccessControlException", "message": "Superuser privilege is required"}}`)
+				return
+			}
+			if ns != QuotaDontSet {
+				dir.ns = ns
+			}
+			if ss != QuotaDontSet {
+				dir.ss = ss
+			}
+		case OP_SETQUOTABYSTORAGETYPE:
+			dir.types[q.Get("storagetype")] = parse("storagespacequota")
+		case OP_GETQUOTAUSAGE:
+			var types []string
+			for name, quota := range dir.types {
+				types = append(types, fmt.Sprintf(`"%s": {"consumed": 0, "quota": %d}`, name, quota))
+			}
+			fmt.Fprintf(rsp, `{"QuotaUsage": {"fileAndDirectoryCount": 1, "quota": %d, "spaceConsumed": 0, "spaceQuota": %d, "typeQuota": {%s}}}`, dir.ns, dir.ss, strings.Join(types, ","))
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
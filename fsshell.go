@@ -2,11 +2,14 @@ package gowfs
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"time"
 )
 
 const MAX_UP_CHUNK int64 = 1 * (1024 * 1024) * 1024 // 1 GB.
@@ -19,6 +22,11 @@ type FsShell struct {
 
 // Appends the specified list of local files to the HDFS path.
 func (shell FsShell) AppendToFile(filePaths []string, hdfsPath string, contenttype string) (bool, error) {
+	return shell.AppendToFileContext(context.Background(), filePaths, hdfsPath, contenttype)
+}
+
+// AppendToFile() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) AppendToFileContext(ctx context.Context, filePaths []string, hdfsPath string, contenttype string) (bool, error) {
 
 	for _, path := range filePaths {
 		file, err := os.Open(path)
@@ -33,7 +41,7 @@ func (shell FsShell) AppendToFile(filePaths []string, hdfsPath string, contentty
 			return false, err
 		}
 
-		_, err = shell.FileSystem.Append(bytes.NewBuffer(data), Path{Name: hdfsPath}, 0, contenttype)
+		_, err = shell.FileSystem.AppendContext(ctx, bytes.NewBuffer(data), Path{Name: hdfsPath}, 0, contenttype)
 		if err != nil {
 			return false, err
 		}
@@ -44,14 +52,19 @@ func (shell FsShell) AppendToFile(filePaths []string, hdfsPath string, contentty
 
 // Returns a writer with the content of the specified files.
 func (shell FsShell) Cat(hdfsPaths []string, writr io.Writer) error {
+	return shell.CatContext(context.Background(), hdfsPaths, writr)
+}
+
+// Cat() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) CatContext(ctx context.Context, hdfsPaths []string, writr io.Writer) error {
 	for _, path := range hdfsPaths {
-		stat, err := shell.FileSystem.GetFileStatus(Path{Name: path})
+		stat, err := shell.FileSystem.GetFileStatusContext(ctx, Path{Name: path})
 		if err != nil {
 			return err
 		}
 		//TODO add code to chunk super large files.
 		if stat.Length < MAX_DOWN_CHUNK {
-			readr, err := shell.FileSystem.Open(Path{Name: path}, 0, stat.Length, 4096)
+			readr, err := shell.FileSystem.OpenContext(ctx, Path{Name: path}, 0, stat.Length, 4096)
 			if err != nil {
 				return err
 			}
@@ -63,8 +76,13 @@ func (shell FsShell) Cat(hdfsPaths []string, writr io.Writer) error {
 
 // Changes the group association of the given hdfs paths.
 func (shell FsShell) Chgrp(hdfsPaths []string, grpName string) (bool, error) {
+	return shell.ChgrpContext(context.Background(), hdfsPaths, grpName)
+}
+
+// Chgrp() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) ChgrpContext(ctx context.Context, hdfsPaths []string, grpName string) (bool, error) {
 	for _, path := range hdfsPaths {
-		_, err := shell.FileSystem.SetOwner(Path{Name: path}, "", grpName)
+		_, err := shell.FileSystem.SetOwnerContext(ctx, Path{Name: path}, "", grpName)
 		if err != nil {
 			return false, err
 		}
@@ -74,8 +92,13 @@ func (shell FsShell) Chgrp(hdfsPaths []string, grpName string) (bool, error) {
 
 // Changes the owner of the specified hdfs paths.
 func (shell FsShell) Chown(hdfsPaths []string, owner string) (bool, error) {
+	return shell.ChownContext(context.Background(), hdfsPaths, owner)
+}
+
+// Chown() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) ChownContext(ctx context.Context, hdfsPaths []string, owner string) (bool, error) {
 	for _, path := range hdfsPaths {
-		_, err := shell.FileSystem.SetOwner(Path{Name: path}, owner, "")
+		_, err := shell.FileSystem.SetOwnerContext(ctx, Path{Name: path}, owner, "")
 		if err != nil {
 			return false, err
 		}
@@ -85,8 +108,13 @@ func (shell FsShell) Chown(hdfsPaths []string, owner string) (bool, error) {
 
 // Changes the filemode of the provided hdfs paths.
 func (shell FsShell) Chmod(hdfsPaths []string, perm os.FileMode) (bool, error) {
+	return shell.ChmodContext(context.Background(), hdfsPaths, perm)
+}
+
+// Chmod() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) ChmodContext(ctx context.Context, hdfsPaths []string, perm os.FileMode) (bool, error) {
 	for _, path := range hdfsPaths {
-		_, err := shell.FileSystem.SetPermission(Path{Name: path}, perm)
+		_, err := shell.FileSystem.SetPermissionContext(ctx, Path{Name: path}, perm)
 		if err != nil {
 			return false, err
 		}
@@ -96,7 +124,12 @@ func (shell FsShell) Chmod(hdfsPaths []string, perm os.FileMode) (bool, error) {
 
 // Tests the existence of a remote HDFS file/directory.
 func (shell FsShell) Exists(hdfsPath string) (bool, error) {
-	_, err := shell.FileSystem.GetFileStatus(Path{Name: hdfsPath})
+	return shell.ExistsContext(context.Background(), hdfsPath)
+}
+
+// Exists() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) ExistsContext(ctx context.Context, hdfsPath string) (bool, error) {
+	_, err := shell.FileSystem.GetFileStatusContext(ctx, Path{Name: hdfsPath})
 	if err != nil {
 		if remoteErr, ok := err.(RemoteException); ok && remoteErr.JavaClassName == "java.io.FileNotFoundException" {
 			return false, nil
@@ -110,6 +143,11 @@ func (shell FsShell) Exists(hdfsPath string) (bool, error) {
 // Copies one specified local file to the remote HDFS server.
 // Uses default permission, blocksize, and replication.
 func (shell FsShell) Put(localFile string, hdfsPath string, overwrite bool) (bool, error) {
+	return shell.PutContext(context.Background(), localFile, hdfsPath, overwrite)
+}
+
+// Put() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) PutContext(ctx context.Context, localFile string, hdfsPath string, overwrite bool) (bool, error) {
 	if _, err := os.Stat(localFile); os.IsNotExist(err) {
 		return false, fmt.Errorf("File %v not found.", localFile)
 	}
@@ -121,7 +159,7 @@ func (shell FsShell) Put(localFile string, hdfsPath string, overwrite bool) (boo
 	defer file.Close()
 
 	// put as a new remote file
-	_, err = shell.FileSystem.Create(
+	_, err = shell.FileSystem.CreateContext(ctx,
 		file,
 		Path{Name: hdfsPath + "/" + µ(path.Split(localFile))[1].(string)},
 		overwrite,
@@ -142,13 +180,18 @@ func (shell FsShell) Put(localFile string, hdfsPath string, overwrite bool) (boo
 // The hdfsPath must be a directory (created if it does not exist).
 // Uses default permission, blocksize, and replication.
 func (shell FsShell) PutMany(files []string, hdfsPath string, overwrite bool) (bool, error) {
+	return shell.PutManyContext(context.Background(), files, hdfsPath, overwrite)
+}
+
+// PutMany() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) PutManyContext(ctx context.Context, files []string, hdfsPath string, overwrite bool) (bool, error) {
 	// if multiple files, put in remote directory
 	if len(files) > 1 {
-		stat, err := shell.FileSystem.GetFileStatus(Path{Name: hdfsPath})
+		stat, err := shell.FileSystem.GetFileStatusContext(ctx, Path{Name: hdfsPath})
 
 		// if remote dir missing, crete it.
 		if remoteErr := err.(RemoteException); remoteErr.JavaClassName == "java.io.FileNotFoundException" {
-			if _, err := shell.FileSystem.MkDirs(Path{Name: hdfsPath}, 0700); err != nil {
+			if _, err := shell.FileSystem.MkDirsContext(ctx, Path{Name: hdfsPath}, 0700); err != nil {
 				return false, err
 			}
 		}
@@ -157,20 +200,25 @@ func (shell FsShell) PutMany(files []string, hdfsPath string, overwrite bool) (b
 		}
 	}
 	for _, file := range files {
-		shell.Put(file, hdfsPath+"/"+µ(path.Split(file))[1].(string), overwrite)
+		shell.PutContext(ctx, file, hdfsPath+"/"+µ(path.Split(file))[1].(string), overwrite)
 	}
 	return true, nil
 }
 
 // Retrieves a remote HDFS file and saves as the specified local file.
 func (shell FsShell) Get(hdfsPath, localFile string) (bool, error) {
+	return shell.GetContext(context.Background(), hdfsPath, localFile)
+}
+
+// Get() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) GetContext(ctx context.Context, hdfsPath, localFile string) (bool, error) {
 	file, err := os.Create(localFile)
 	if err != nil {
 		return false, err
 	}
 	defer file.Close()
 
-	reader, err := shell.FileSystem.Open(Path{Name: hdfsPath}, 0, 0, 0)
+	reader, err := shell.FileSystem.OpenContext(ctx, Path{Name: hdfsPath}, 0, 0, 0)
 	if err != nil {
 		return false, err
 	}
@@ -195,10 +243,15 @@ func (shell FsShell) Get(hdfsPath, localFile string) (bool, error) {
 
 // Copies local file to remote destination, then local file is removed.
 func (shell FsShell) MoveFromLocal(localFile, hdfsPath string, overwrite bool) (bool, error) {
-	ok, err := shell.Put(localFile, hdfsPath, overwrite)
+	return shell.MoveFromLocalContext(context.Background(), localFile, hdfsPath, overwrite)
+}
+
+// MoveFromLocal() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) MoveFromLocalContext(ctx context.Context, localFile, hdfsPath string, overwrite bool) (bool, error) {
+	ok, err := shell.PutContext(ctx, localFile, hdfsPath, overwrite)
 	// validate operation, then remove local
 	if ok && err == nil {
-		hdfStat, err := shell.FileSystem.GetFileStatus(Path{Name: path.Join(hdfsPath, path.Base(localFile))})
+		hdfStat, err := shell.FileSystem.GetFileStatusContext(ctx, Path{Name: path.Join(hdfsPath, path.Base(localFile))})
 		if err != nil {
 			return false, fmt.Errorf("Unable to verify remote file. err is %v", err.Error())
 		}
@@ -223,8 +276,13 @@ func (shell FsShell) MoveFromLocal(localFile, hdfsPath string, overwrite bool) (
 
 // Copies remote HDFS file locally.  The remote file is then removed.
 func (shell FsShell) MoveToLocal(hdfsPath, localFile string) (bool, error) {
-	hdfStat, err := shell.FileSystem.GetFileStatus(Path{Name: hdfsPath})
-	_, err = shell.Get(hdfsPath, localFile)
+	return shell.MoveToLocalContext(context.Background(), hdfsPath, localFile)
+}
+
+// MoveToLocal() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) MoveToLocalContext(ctx context.Context, hdfsPath, localFile string) (bool, error) {
+	hdfStat, err := shell.FileSystem.GetFileStatusContext(ctx, Path{Name: hdfsPath})
+	_, err = shell.GetContext(ctx, hdfsPath, localFile)
 	if err != nil {
 		return false, err
 	}
@@ -246,7 +304,7 @@ func (shell FsShell) MoveToLocal(hdfsPath, localFile string) (bool, error) {
 	}
 
 	// remove remote File
-	ok, err := shell.FileSystem.Delete(Path{Name: hdfsPath}, false)
+	ok, err := shell.FileSystem.DeleteContext(ctx, Path{Name: hdfsPath}, false)
 	if err != nil {
 		return false, fmt.Errorf("Unable to remove remote %s file: %s", hdfsPath, err.Error())
 	}
@@ -256,9 +314,294 @@ func (shell FsShell) MoveToLocal(hdfsPath, localFile string) (bool, error) {
 
 // Removes the specified HDFS source.
 func (shell FsShell) Rm(hdfsPath string) (bool, error) {
+	return shell.RmContext(context.Background(), hdfsPath)
+}
+
+// Rm() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) RmContext(ctx context.Context, hdfsPath string) (bool, error) {
 	return false, fmt.Errorf("Function is unimplemented.")
 }
 
+// Returns the xattrs of the HDFS path (and, when recursive, of everything
+// below it), keyed by path.  When names are provided, only those xattrs
+// are returned; paths without any of them are left out.
+// See 'hdfs dfs -getfattr'.
+func (shell FsShell) GetFattr(hdfsPath string, names []string, encoding XAttrEncoding, recursive bool) (map[string][]XAttr, error) {
+	return shell.GetFattrContext(context.Background(), hdfsPath, names, encoding, recursive)
+}
+
+// GetFattr() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) GetFattrContext(ctx context.Context, hdfsPath string, names []string, encoding XAttrEncoding, recursive bool) (map[string][]XAttr, error) {
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+	result := make(map[string][]XAttr)
+	err := shell.walk(ctx, hdfsPath, recursive, func(p string, stat FileStatus) error {
+		// all xattrs are fetched: asking for missing ones is an error.
+		xattrs, err := shell.FileSystem.GetXAttrsContext(ctx, Path{Name: p}, encoding)
+		if err != nil {
+			return err
+		}
+		var found []XAttr
+		for _, xattr := range xattrs {
+			if len(wanted) == 0 || wanted[xattr.Name] {
+				found = append(found, xattr)
+			}
+		}
+		if len(found) > 0 {
+			result[p] = found
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Sets the xattr name on the HDFS path (and, when recursive, on everything
+// below it).  The xattr is created or replaced.
+// See 'hdfs dfs -setfattr'.
+func (shell FsShell) SetFattr(hdfsPath string, name string, value []byte, recursive bool) (bool, error) {
+	return shell.SetFattrContext(context.Background(), hdfsPath, name, value, recursive)
+}
+
+// SetFattr() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) SetFattrContext(ctx context.Context, hdfsPath string, name string, value []byte, recursive bool) (bool, error) {
+	err := shell.walk(ctx, hdfsPath, recursive, func(p string, stat FileStatus) error {
+		_, err := shell.FileSystem.SetXAttrContext(ctx, Path{Name: p}, name, value)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Returns the ACL of the HDFS path (and, when recursive, of everything
+// below it), keyed by path.
+// See 'hdfs dfs -getfacl'.
+func (shell FsShell) Getfacl(hdfsPath string, recursive bool) (map[string]AclStatus, error) {
+	return shell.GetfaclContext(context.Background(), hdfsPath, recursive)
+}
+
+// Getfacl() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) GetfaclContext(ctx context.Context, hdfsPath string, recursive bool) (map[string]AclStatus, error) {
+	result := make(map[string]AclStatus)
+	err := shell.walk(ctx, hdfsPath, recursive, func(p string, stat FileStatus) error {
+		acl, err := shell.FileSystem.GetAclStatusContext(ctx, Path{Name: p})
+		if err != nil {
+			return err
+		}
+		result[p] = acl
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Changes the ACL of the HDFS path (and, when recursive, of everything
+// below it).  op selects the change like the 'hdfs dfs -setfacl' options:
+// OP_SETACL (--set), OP_MODIFYACLENTRIES (-m), OP_REMOVEACLENTRIES (-x),
+// OP_REMOVEDEFAULTACL (-k) or OP_REMOVEACL (-b).  spec is ignored by the
+// last two.  Default entries only apply to directories.
+func (shell FsShell) Setfacl(hdfsPath string, op string, spec AclSpec, recursive bool) (bool, error) {
+	return shell.SetfaclContext(context.Background(), hdfsPath, op, spec, recursive)
+}
+
+// Setfacl() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) SetfaclContext(ctx context.Context, hdfsPath string, op string, spec AclSpec, recursive bool) (bool, error) {
+	fs := shell.FileSystem
+	switch op {
+	case OP_SETACL, OP_MODIFYACLENTRIES, OP_REMOVEACLENTRIES, OP_REMOVEDEFAULTACL, OP_REMOVEACL:
+	default:
+		return false, fmt.Errorf("Setfacl() - unsupported ACL operation %s.", op)
+	}
+
+	err := shell.walk(ctx, hdfsPath, recursive, func(p string, stat FileStatus) error {
+		entries := spec
+		if stat.Type != "DIRECTORY" {
+			// files have no default ACL.
+			if op == OP_REMOVEDEFAULTACL {
+				return nil
+			}
+			entries = nil
+			for _, e := range spec {
+				if e.Scope != AclScopeDefault {
+					entries = append(entries, e)
+				}
+			}
+			if len(entries) == 0 && op != OP_REMOVEACL {
+				return nil
+			}
+		}
+
+		var err error
+		switch op {
+		case OP_SETACL:
+			_, err = fs.SetAclContext(ctx, Path{Name: p}, entries)
+		case OP_MODIFYACLENTRIES:
+			_, err = fs.ModifyAclEntriesContext(ctx, Path{Name: p}, entries)
+		case OP_REMOVEACLENTRIES:
+			_, err = fs.RemoveAclEntriesContext(ctx, Path{Name: p}, entries)
+		case OP_REMOVEDEFAULTACL:
+			_, err = fs.RemoveDefaultAclContext(ctx, Path{Name: p})
+		case OP_REMOVEACL:
+			_, err = fs.RemoveAclContext(ctx, Path{Name: p})
+		}
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Keeps the n most recent snapshots of the HDFS directory and deletes
+// the others.  Returns the names of the deleted snapshots, oldest first.
+func (shell FsShell) KeepLastSnapshots(hdfsPath string, n int) ([]string, error) {
+	return shell.KeepLastSnapshotsContext(context.Background(), hdfsPath, n)
+}
+
+// KeepLastSnapshots() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) KeepLastSnapshotsContext(ctx context.Context, hdfsPath string, n int) ([]string, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("KeepLastSnapshots() - param n cannot be negative.")
+	}
+	snapshots, err := shell.sortedSnapshots(ctx, hdfsPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) <= n {
+		return nil, nil
+	}
+	return shell.deleteSnapshots(ctx, hdfsPath, snapshots[:len(snapshots)-n])
+}
+
+// Deletes the snapshots of the HDFS directory created more than age ago.
+// Returns the names of the deleted snapshots, oldest first.
+func (shell FsShell) DeleteSnapshotsOlderThan(hdfsPath string, age time.Duration) ([]string, error) {
+	return shell.DeleteSnapshotsOlderThanContext(context.Background(), hdfsPath, age)
+}
+
+// DeleteSnapshotsOlderThan() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) DeleteSnapshotsOlderThanContext(ctx context.Context, hdfsPath string, age time.Duration) ([]string, error) {
+	snapshots, err := shell.sortedSnapshots(ctx, hdfsPath)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-age)
+	var old []SnapshotStatus
+	for _, s := range snapshots {
+		if s.CreationTime().Before(cutoff) {
+			old = append(old, s)
+		}
+	}
+	return shell.deleteSnapshots(ctx, hdfsPath, old)
+}
+
+// Returns the snapshots of hdfsPath, oldest first.
+func (shell FsShell) sortedSnapshots(ctx context.Context, hdfsPath string) ([]SnapshotStatus, error) {
+	snapshots, err := shell.FileSystem.GetSnapshotListContext(ctx, Path{Name: hdfsPath})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		a, b := snapshots[i], snapshots[j]
+		if a.DirStatus.ModificationTime != b.DirStatus.ModificationTime {
+			return a.DirStatus.ModificationTime < b.DirStatus.ModificationTime
+		}
+		return a.SnapshotID < b.SnapshotID
+	})
+	return snapshots, nil
+}
+
+// Deletes snapshots of hdfsPath, stopping at the first failure.  Returns
+// the names of the snapshots deleted so far.
+func (shell FsShell) deleteSnapshots(ctx context.Context, hdfsPath string, snapshots []SnapshotStatus) ([]string, error) {
+	var deleted []string
+	for _, s := range snapshots {
+		if _, err := shell.FileSystem.DeleteSnapshotContext(ctx, Path{Name: hdfsPath}, s.Name()); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, s.Name())
+	}
+	return deleted, nil
+}
+
+// Creates the HDFS directory (and its parents) with the namespace and
+// storage space quotas, see FileSystem.SetQuota().  The directories
+// created here, missing parents included, are removed again when the
+// quotas cannot be set.
+func (shell FsShell) MkdirWithQuota(hdfsPath string, perm os.FileMode, nsQuota, ssQuota int64) (bool, error) {
+	return shell.MkdirWithQuotaContext(context.Background(), hdfsPath, perm, nsQuota, ssQuota)
+}
+
+// MkdirWithQuota() with a context.Context to cancel the request or set a deadline.
+func (shell FsShell) MkdirWithQuotaContext(ctx context.Context, hdfsPath string, perm os.FileMode, nsQuota, ssQuota int64) (bool, error) {
+	if !validQuota(nsQuota) || !validQuota(ssQuota) {
+		return false, fmt.Errorf("MkdirWithQuota() - quotas must be positive, QuotaReset or QuotaDontSet.")
+	}
+	fs := shell.FileSystem
+	// directories MkDirs will create, deepest first.
+	var missing []string
+	for p := path.Clean(hdfsPath); p != "/" && p != "."; p = path.Dir(p) {
+		exists, err := shell.ExistsContext(ctx, p)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			break
+		}
+		missing = append(missing, p)
+	}
+	if ok, err := fs.MkDirsContext(ctx, Path{Name: hdfsPath}, perm); err != nil || !ok {
+		return false, err
+	}
+	if _, err := fs.SetQuotaContext(ctx, Path{Name: hdfsPath}, nsQuota, ssQuota); err != nil {
+		// not recursive: directories filled meanwhile are kept.
+		for _, p := range missing {
+			if _, derr := fs.DeleteContext(ctx, Path{Name: p}, false); derr != nil {
+				return false, fmt.Errorf("MkdirWithQuota() - %w (unable to remove created directory %s: %s)", err, p, derr.Error())
+			}
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Calls fn for hdfsPath then, when recursive and hdfsPath is a directory,
+// for every file and directory below it.
+func (shell FsShell) walk(ctx context.Context, hdfsPath string, recursive bool, fn func(p string, stat FileStatus) error) error {
+	stat, err := shell.FileSystem.GetFileStatusContext(ctx, Path{Name: hdfsPath})
+	if err != nil {
+		return err
+	}
+	return shell.walkStatus(ctx, hdfsPath, stat, recursive, fn)
+}
+
+func (shell FsShell) walkStatus(ctx context.Context, hdfsPath string, stat FileStatus, recursive bool, fn func(p string, stat FileStatus) error) error {
+	if err := fn(hdfsPath, stat); err != nil {
+		return err
+	}
+	if !recursive || stat.Type != "DIRECTORY" {
+		return nil
+	}
+	children, err := shell.FileSystem.ListStatusContext(ctx, Path{Name: hdfsPath})
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := shell.walkStatus(ctx, path.Join(hdfsPath, child.PathSuffix), child, recursive, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TODO: slirp file in x Gbyte chunks when file.Stat() >> X.
 //       this is to avoid blow up memory on large files.
 func slirpLocalFile(file os.File, offset int64) ([]byte, int64, error) {
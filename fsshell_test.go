@@ -10,6 +10,7 @@ import "fmt"
 import "log"
 
 import "strings"
+import "time"
 
 func Test_AppendToFile(t *testing.T) {
 	// setup test file
@@ -37,7 +38,7 @@ func Test_AppendToFile(t *testing.T) {
 	fs, _ := NewFileSystem(Configuration{Addr: url.Host})
 	shell := FsShell{FileSystem: fs}
 
-	shell.AppendToFile([]string{f1.Name(), f2.Name()}, "/testing/location")
+	shell.AppendToFile([]string{f1.Name(), f2.Name()}, "/testing/location", "")
 
 }
 
@@ -108,6 +109,166 @@ func Test_Chmod(t *testing.T) {
 // 	}
 // }
 
+func Test_SetFattr_GetFattr(t *testing.T) {
+	server := mockServerFor_XAttrs()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	shell := FsShell{FileSystem: fs}
+
+	if _, err := shell.SetFattr("/data", "user.job", []byte("ingest"), true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shell.SetFattr("/data/sub/b.txt", "user.schema", []byte("v2"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := shell.GetFattr("/data", nil, XAttrEncodingText, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 || len(all["/data/sub/b.txt"]) != 2 {
+		t.Errorf("Expecting user.job on 4 paths and 2 xattrs on /data/sub/b.txt, but got %v", all)
+	}
+
+	schema, err := shell.GetFattr("/data", []string{"user.schema"}, XAttrEncodingText, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema) != 1 || schema["/data/sub/b.txt"][0].Value != `"v2"` {
+		t.Errorf("Expecting user.schema only on /data/sub/b.txt, but got %v", schema)
+	}
+
+	top, _ := shell.GetFattr("/data", nil, XAttrEncodingText, false)
+	if len(top) != 1 {
+		t.Errorf("Expecting only /data without recursion, but got %v", top)
+	}
+}
+
+func Test_Setfacl_Getfacl(t *testing.T) {
+	server := mockServerFor_Acl()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	shell := FsShell{FileSystem: fs}
+
+	spec, _ := ParseAclSpec("user:alice:rwx,default:user:alice:r-x")
+	if _, err := shell.Setfacl("/data", OP_MODIFYACLENTRIES, spec, true); err != nil {
+		t.Fatal(err)
+	}
+
+	acls, err := shell.Getfacl("/data", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acls) != 4 {
+		t.Fatalf("Expecting ACLs of 4 paths, but got %v", acls)
+	}
+	if len(acls["/data/sub"].Entries) != 2 || len(acls["/data/sub/b.txt"].Entries) != 1 {
+		t.Errorf("Expecting default entries on directories only, but got %v", acls)
+	}
+
+	if _, err := shell.Setfacl("/data", OP_REMOVEACL, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	acls, _ = shell.Getfacl("/data", true)
+	if len(acls["/data/a.txt"].Entries) != 0 {
+		t.Errorf("Expecting ACL removed, but got %v", acls)
+	}
+
+	if _, err := shell.Setfacl("/data", OP_LISTSTATUS, spec, false); err == nil {
+		t.Error("Expecting an error for a non-ACL operation.")
+	}
+}
+
+func Test_KeepLastSnapshots(t *testing.T) {
+	server := mockServerFor_Snapshots()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	shell := FsShell{FileSystem: fs}
+
+	deleted, err := shell.KeepLastSnapshots("/data", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(deleted, ",") != "s1,s2" {
+		t.Errorf("Expecting s1,s2 deleted, but got %v", deleted)
+	}
+	deleted, _ = shell.KeepLastSnapshots("/data", 2)
+	if len(deleted) != 0 {
+		t.Errorf("Expecting nothing deleted, but got %v", deleted)
+	}
+	if _, err := shell.KeepLastSnapshots("/data", -1); err == nil {
+		t.Error("Expecting an error for a negative count.")
+	}
+}
+
+func Test_DeleteSnapshotsOlderThan(t *testing.T) {
+	server := mockServerFor_Snapshots()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	shell := FsShell{FileSystem: fs}
+
+	deleted, err := shell.DeleteSnapshotsOlderThan("/data", 36*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(deleted, ",") != "s1,s2,s3" {
+		t.Errorf("Expecting s1,s2,s3 deleted, but got %v", deleted)
+	}
+	snapshots, _ := fs.GetSnapshotList(Path{Name: "/data"})
+	if len(snapshots) != 1 || snapshots[0].Name() != "s4" {
+		t.Errorf("Expecting only s4 kept, but got %v", snapshots)
+	}
+}
+
+func Test_MkdirWithQuota(t *testing.T) {
+	server := mockServerFor_Quota()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	shell := FsShell{FileSystem: fs}
+
+	if _, err := shell.MkdirWithQuota("/projects/p2", 0750, 100, QuotaDontSet); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := fs.GetQuotaUsage(Path{Name: "/projects/p2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Quota != 100 || usage.SpaceQuota != -1 {
+		t.Errorf("Expecting a namespace quota of 100, but got %v", usage)
+	}
+
+	// the quota is refused, the new directory is removed.
+	if _, err := shell.MkdirWithQuota("/projects/p3", 0750, 1<<50, QuotaDontSet); err == nil {
+		t.Fatal("Expecting the refused quota to be reported.")
+	}
+	if exists, _ := shell.Exists("/projects/p3"); exists {
+		t.Error("Expecting /projects/p3 to be removed.")
+	}
+	// so are the parents it created, but not the existing ones.
+	if _, err := shell.MkdirWithQuota("/projects/p3/a/b", 0750, 1<<50, QuotaDontSet); err == nil {
+		t.Fatal("Expecting the refused quota to be reported.")
+	}
+	if exists, _ := shell.Exists("/projects/p3"); exists {
+		t.Error("Expecting /projects/p3 to be removed.")
+	}
+	if exists, _ := shell.Exists("/projects"); !exists {
+		t.Error("Expecting /projects to be kept.")
+	}
+	if _, err := shell.MkdirWithQuota("/projects/p4", 0750, -5, 10); err == nil {
+		t.Error("Expecting an error for an invalid quota.")
+	}
+}
+
 func Test_PutOne(t *testing.T) {
 	f1, err := createTestFile("test-file.txt")
 	if err != nil {
@@ -210,7 +371,7 @@ func mockServerFor_FsShellOpen() *httptest.Server {
 	handler := func(rsp http.ResponseWriter, req *http.Request) {
 		q := req.URL.Query()
 		if q.Get("op") == OP_GETFILESTATUS {
-			fmt.Fprintln(rsp, fileStatusRsp)
+			fmt.Fprint(rsp, fileStatusRsp)
 		}
 		if q.Get("op") == OP_OPEN {
 			fmt.Fprintln(rsp, fsShellOpenRsp)
@@ -0,0 +1,469 @@
+package gowfs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type of a change between two snapshots.
+type SnapshotDiffType string
+
+const (
+	SnapshotDiffCreate SnapshotDiffType = "CREATE"
+	SnapshotDiffModify SnapshotDiffType = "MODIFY"
+	SnapshotDiffDelete SnapshotDiffType = "DELETE"
+	SnapshotDiffRename SnapshotDiffType = "RENAME"
+)
+
+// Returns the name of the snapshot (the last element of FullPath).
+func (s SnapshotStatus) Name() string {
+	return path.Base(s.FullPath)
+}
+
+// Returns the creation time of the snapshot.
+func (s SnapshotStatus) CreationTime() time.Time {
+	return msToTime(s.DirStatus.ModificationTime)
+}
+
+// Returns the full path of the snapshottable directory.
+func (s SnapshottableDirectoryStatus) FullPath() string {
+	return path.Join(s.ParentFullPath, s.DirStatus.PathSuffix)
+}
+
+// Allows snapshots of the specified directory.  Requires superuser
+// privilege.
+// See HDFS DistributedFileSystem.allowSnapshot()
+func (fs *FileSystem) AllowSnapshot(p Path) (bool, error) {
+	return fs.AllowSnapshotContext(context.Background(), p)
+}
+
+// AllowSnapshot() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) AllowSnapshotContext(ctx context.Context, p Path) (bool, error) {
+	return fs.putOperation(ctx, p, map[string]string{"op": OP_ALLOWSNAPSHOT})
+}
+
+// Disallows snapshots of the specified directory.  Existing snapshots
+// must be deleted first.  Requires superuser privilege.
+// See HDFS DistributedFileSystem.disallowSnapshot()
+func (fs *FileSystem) DisallowSnapshot(p Path) (bool, error) {
+	return fs.DisallowSnapshotContext(context.Background(), p)
+}
+
+// DisallowSnapshot() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) DisallowSnapshotContext(ctx context.Context, p Path) (bool, error) {
+	return fs.putOperation(ctx, p, map[string]string{"op": OP_DISALLOWSNAPSHOT})
+}
+
+// Creates a snapshot of the specified directory and returns its path
+// (i.e. /data/.snapshot/s1).  When name is empty, the namenode names the
+// snapshot after the current time.
+// See HDFS FileSystem.createSnapshot()
+func (fs *FileSystem) CreateSnapshot(p Path, name string) (Path, error) {
+	return fs.CreateSnapshotContext(context.Background(), p, name)
+}
+
+// CreateSnapshot() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) CreateSnapshotContext(ctx context.Context, p Path, name string) (Path, error) {
+	params := map[string]string{"op": OP_CREATESNAPSHOT}
+	if name != "" {
+		params["snapshotname"] = name
+	}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return Path{}, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return Path{}, err
+	}
+
+	return Path{Name: hdfsData.Path}, nil
+}
+
+// Renames snapshot oldName of the specified directory to newName.
+// See HDFS FileSystem.renameSnapshot()
+func (fs *FileSystem) RenameSnapshot(p Path, oldName, newName string) (bool, error) {
+	return fs.RenameSnapshotContext(context.Background(), p, oldName, newName)
+}
+
+// RenameSnapshot() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) RenameSnapshotContext(ctx context.Context, p Path, oldName, newName string) (bool, error) {
+	if oldName == "" || newName == "" {
+		return false, fmt.Errorf("RenameSnapshot() - snapshot names cannot be empty.")
+	}
+	params := map[string]string{
+		"op":              OP_RENAMESNAPSHOT,
+		"oldsnapshotname": oldName,
+		"snapshotname":    newName}
+	return fs.putOperation(ctx, p, params)
+}
+
+// Deletes snapshot name of the specified directory.
+// See HDFS FileSystem.deleteSnapshot()
+func (fs *FileSystem) DeleteSnapshot(p Path, name string) (bool, error) {
+	return fs.DeleteSnapshotContext(context.Background(), p, name)
+}
+
+// DeleteSnapshot() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) DeleteSnapshotContext(ctx context.Context, p Path, name string) (bool, error) {
+	if name == "" {
+		return false, fmt.Errorf("DeleteSnapshot() - param name cannot be empty.")
+	}
+	params := map[string]string{"op": OP_DELETESNAPSHOT, "snapshotname": name}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return false, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "DELETE", u.String(), nil)
+	_, err = requestHdfsData(fs.client, *req)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Returns the snapshottable directories visible to the user.
+// See HDFS DistributedFileSystem.getSnapshottableDirListing()
+func (fs *FileSystem) GetSnapshottableDirectoryList() ([]SnapshottableDirectoryStatus, error) {
+	return fs.GetSnapshottableDirectoryListContext(context.Background())
+}
+
+// GetSnapshottableDirectoryList() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetSnapshottableDirectoryListContext(ctx context.Context) ([]SnapshottableDirectoryStatus, error) {
+	params := map[string]string{"op": OP_GETSNAPSHOTTABLEDIRECTORYLIST}
+	u, err := fs.requestUrl(ctx, &Path{Name: "/"}, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return nil, err
+	}
+
+	return hdfsData.SnapshotDirs, nil
+}
+
+// Returns the snapshots of the specified directory.
+// See HDFS DistributedFileSystem.getSnapshotListing()
+func (fs *FileSystem) GetSnapshotList(p Path) ([]SnapshotStatus, error) {
+	return fs.GetSnapshotListContext(context.Background(), p)
+}
+
+// GetSnapshotList() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetSnapshotListContext(ctx context.Context, p Path) ([]SnapshotStatus, error) {
+	params := map[string]string{"op": OP_GETSNAPSHOTLIST}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return nil, err
+	}
+
+	return hdfsData.SnapshotList, nil
+}
+
+// Returns the changes of the specified directory from snapshot from to
+// snapshot to.  An empty snapshot name denotes the current state of the
+// directory.  Large differences are fetched in pages, see SnapshotDiff().
+// See HDFS DistributedFileSystem.getSnapshotDiffReport()
+func (fs *FileSystem) GetSnapshotDiff(dir Path, from, to string) ([]SnapshotDiffEntry, error) {
+	return fs.GetSnapshotDiffContext(context.Background(), dir, from, to)
+}
+
+// GetSnapshotDiff() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetSnapshotDiffContext(ctx context.Context, dir Path, from, to string) ([]SnapshotDiffEntry, error) {
+	it := fs.SnapshotDiffContext(ctx, dir, from, to)
+	var entries []SnapshotDiffEntry
+	for it.Next() {
+		entries = append(entries, it.Entry())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Returns an iterator over the changes of the specified directory from
+// snapshot from to snapshot to.  Pages are requested with
+// GETSNAPSHOTDIFFLISTING as the iteration goes; renames are reported once
+// the last page is read.  Servers without GETSNAPSHOTDIFFLISTING are sent
+// a single GETSNAPSHOTDIFF.
+func (fs *FileSystem) SnapshotDiff(dir Path, from, to string) *SnapshotDiffIterator {
+	return fs.SnapshotDiffContext(context.Background(), dir, from, to)
+}
+
+// SnapshotDiff() with a context.Context to cancel the requests or set a deadline.
+func (fs *FileSystem) SnapshotDiffContext(ctx context.Context, dir Path, from, to string) *SnapshotDiffIterator {
+	return &SnapshotDiffIterator{
+		fs:      fs,
+		ctx:     ctx,
+		dir:     dir,
+		from:    from,
+		to:      to,
+		listing: true,
+		index:   -1,
+		parents: make(map[int64]string),
+		renames: make(map[int64]*snapshotDiffReference),
+	}
+}
+
+// Iterator over the changes between two snapshots.
+//
+//	it := fs.SnapshotDiff(dir, "s1", "s2")
+//	for it.Next() {
+//		entry := it.Entry()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type SnapshotDiffIterator struct {
+	fs       *FileSystem
+	ctx      context.Context
+	dir      Path
+	from, to string
+
+	listing   bool   // false once the server is found without GETSNAPSHOTDIFFLISTING
+	started   bool   // a page was received
+	startPath string // position of the next page
+	index     int64
+	done      bool // all pages were received
+	flushed   bool // renames were reported
+
+	pending     []SnapshotDiffEntry
+	parents     map[int64]string                 // path of the modified directories, by DirId
+	renames     map[int64]*snapshotDiffReference // by FileId, until the last page
+	renameOrder []int64
+	entry       SnapshotDiffEntry
+	err         error
+}
+
+// Created and deleted references to a file, in paths of the earlier
+// snapshot (source) and of the later one (target).
+type snapshotDiffReference struct {
+	source, target string
+	fromEarlier    bool
+}
+
+// Advances to the next change, requesting the next page when needed.
+// Returns false at the end of the changes or on error (see Err()).
+func (it *SnapshotDiffIterator) Next() bool {
+	for len(it.pending) == 0 {
+		if it.err != nil || it.flushed {
+			return false
+		}
+		if it.done {
+			it.flushRenames()
+			continue
+		}
+		it.err = it.fetch()
+	}
+	it.entry, it.pending = it.pending[0], it.pending[1:]
+	return true
+}
+
+// Returns the current change.
+func (it *SnapshotDiffIterator) Entry() SnapshotDiffEntry {
+	return it.entry
+}
+
+// Returns the error that stopped the iteration, if any.
+func (it *SnapshotDiffIterator) Err() error {
+	return it.err
+}
+
+// Requests the next page of the listing, or the whole report when the
+// server has no GETSNAPSHOTDIFFLISTING.
+func (it *SnapshotDiffIterator) fetch() error {
+	if !it.listing {
+		report, err := it.request(OP_GETSNAPSHOTDIFF, nil)
+		if err != nil {
+			return err
+		}
+		it.pending = report.SnapshotDiff.DiffList
+		it.done, it.flushed = true, true
+		return nil
+	}
+
+	params := map[string]string{
+		"snapshotdiffstartpath": it.startPath,
+		"snapshotdiffindex":     strconv.FormatInt(it.index, 10)}
+	hdfsData, err := it.request(OP_GETSNAPSHOTDIFFLISTING, params)
+	if err != nil {
+		if !it.started && unsupportedOperation(err, OP_GETSNAPSHOTDIFFLISTING) {
+			it.listing = false
+			return it.fetch()
+		}
+		return err
+	}
+	it.started = true
+	it.addPage(hdfsData.DiffListing)
+	return nil
+}
+
+// Adds the changes of a listing page to pending, like Hadoop's
+// SnapshotDiffReportGenerator.  Created and deleted entries only hold the
+// name of the child; their directory is the modified entry with the same
+// DirId, which may come in an earlier page.  Each modified directory is
+// followed by its created and deleted children.
+func (it *SnapshotDiffIterator) addPage(page SnapshotDiffReportListing) {
+	children := make(map[int64][]SnapshotDiffEntry)
+	var order []int64 // DirIds with children, in listing order
+	add := func(dirId int64, entry SnapshotDiffEntry) {
+		if _, ok := children[dirId]; !ok {
+			order = append(order, dirId)
+		}
+		children[dirId] = append(children[dirId], entry)
+	}
+	// CREATE and DELETE are relative to from: they swap when from is later.
+	created, deleted := SnapshotDiffCreate, SnapshotDiffDelete
+	if !page.IsFromEarlier {
+		created, deleted = deleted, created
+	}
+
+	listed := make(map[int64]bool) // directories modified in this page
+	for _, e := range page.ModifyList {
+		if e.FileId == e.DirId {
+			it.parents[e.DirId] = e.SourcePath
+			listed[e.DirId] = true
+		}
+	}
+	for _, e := range page.CreateList {
+		p := it.childPath(e)
+		if !e.IsReference {
+			add(e.DirId, SnapshotDiffEntry{Type: created, SourcePath: p})
+			continue
+		}
+		if ref := it.reference(e.FileId, page.IsFromEarlier); ref.target == "" {
+			ref.target = p
+		}
+	}
+	for _, e := range page.DeleteList {
+		p := it.childPath(e)
+		if !e.IsReference {
+			add(e.DirId, SnapshotDiffEntry{Type: deleted, SourcePath: p})
+			continue
+		}
+		ref := it.reference(e.FileId, page.IsFromEarlier)
+		ref.source = p
+		if e.TargetPath != "" {
+			ref.target = e.TargetPath // the full path, not only the name
+		}
+	}
+
+	// children of a directory listed in an earlier page come first.
+	for _, dirId := range order {
+		if !listed[dirId] {
+			it.pending = append(it.pending, children[dirId]...)
+		}
+	}
+	for _, e := range page.ModifyList {
+		it.pending = append(it.pending, SnapshotDiffEntry{Type: SnapshotDiffModify, SourcePath: e.SourcePath})
+		if e.FileId == e.DirId {
+			it.pending = append(it.pending, children[e.DirId]...)
+			delete(children, e.DirId)
+		}
+	}
+
+	it.startPath, it.index = page.LastPath, page.LastIndex
+	it.done = page.LastPath == "" && page.LastIndex == -1
+}
+
+// Returns the path of a created or deleted entry, relative to the
+// snapshot root.
+func (it *SnapshotDiffIterator) childPath(e SnapshotDiffListingEntry) string {
+	parent, ok := it.parents[e.DirId]
+	if !ok {
+		return e.SourcePath
+	}
+	return path.Join(parent, path.Base(e.SourcePath))
+}
+
+func (it *SnapshotDiffIterator) request(op string, params map[string]string) (HdfsJsonData, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	params["op"] = op
+	params["oldsnapshotname"] = it.from
+	params["snapshotname"] = it.to
+	u, err := it.fs.requestUrl(it.ctx, &it.dir, &params)
+	if err != nil {
+		return HdfsJsonData{}, err
+	}
+
+	req, _ := http.NewRequestWithContext(it.ctx, "GET", u.String(), nil)
+	return requestHdfsData(it.fs.client, *req)
+}
+
+// Returns the references to fileId, the created and deleted references
+// of a renamed file may come in different pages.
+func (it *SnapshotDiffIterator) reference(fileId int64, fromEarlier bool) *snapshotDiffReference {
+	ref, ok := it.renames[fileId]
+	if !ok {
+		ref = &snapshotDiffReference{fromEarlier: fromEarlier}
+		it.renames[fileId] = ref
+		it.renameOrder = append(it.renameOrder, fileId)
+	}
+	return ref
+}
+
+// Reports the renames, references without a counterpart are reported as
+// created or deleted.
+func (it *SnapshotDiffIterator) flushRenames() {
+	for _, fileId := range it.renameOrder {
+		ref := it.renames[fileId]
+		var entry SnapshotDiffEntry
+		switch {
+		case ref.source == "":
+			entry = SnapshotDiffEntry{Type: SnapshotDiffCreate, SourcePath: ref.target}
+		case ref.target == "":
+			entry = SnapshotDiffEntry{Type: SnapshotDiffDelete, SourcePath: ref.source}
+		default:
+			entry = SnapshotDiffEntry{Type: SnapshotDiffRename, SourcePath: ref.source, TargetPath: ref.target}
+		}
+		if !ref.fromEarlier {
+			switch entry.Type {
+			case SnapshotDiffCreate:
+				entry.Type = SnapshotDiffDelete
+			case SnapshotDiffDelete:
+				entry.Type = SnapshotDiffCreate
+			case SnapshotDiffRename:
+				entry.SourcePath, entry.TargetPath = entry.TargetPath, entry.SourcePath
+			}
+		}
+		it.pending = append(it.pending, entry)
+	}
+	it.renames, it.renameOrder = nil, nil
+	it.flushed = true
+}
+
+// Returns true when err is the namenode rejecting op as unknown (i.e.
+// GETSNAPSHOTDIFFLISTING before Hadoop 3.3).
+func unsupportedOperation(err error, op string) bool {
+	re, ok := err.(RemoteException)
+	if !ok {
+		return false
+	}
+	switch re.Exception {
+	case "UnsupportedOperationException":
+		return true
+	case "IllegalArgumentException":
+		return strings.Contains(re.Message, op)
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package gowfs
+
+import "fmt"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "sort"
+import "strings"
+import "sync"
+import "testing"
+import "time"
+
+func Test_Snapshots(t *testing.T) {
+	server := mockServerFor_Snapshots()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	p := Path{Name: "/data"}
+
+	if _, err := fs.AllowSnapshot(p); err != nil {
+		t.Fatal(err)
+	}
+	dirs, err := fs.GetSnapshottableDirectoryList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 1 || dirs[0].FullPath() != "/data" || dirs[0].SnapshotNumber != 4 {
+		t.Errorf("Expecting /data with 4 snapshots, but got %v", dirs)
+	}
+
+	snapshot, err := fs.CreateSnapshot(p, "s5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Name != "/data/.snapshot/s5" {
+		t.Errorf("Expecting snapshot path /data/.snapshot/s5, but got %s", snapshot.Name)
+	}
+	if _, err := fs.RenameSnapshot(p, "s5", "latest"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.DeleteSnapshot(p, "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.DeleteSnapshot(p, "s1"); err == nil {
+		t.Error("Expecting an error when deleting a missing snapshot.")
+	}
+
+	snapshots, err := fs.GetSnapshotList(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range snapshots {
+		names = append(names, s.Name())
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "latest,s2,s3,s4" {
+		t.Errorf("Expecting snapshots latest,s2,s3,s4, but got %v", names)
+	}
+}
+
+func Test_GetSnapshotDiff(t *testing.T) {
+	tests := []struct {
+		from, to string
+		expected string
+	}{
+		{"s1", "s2", "MODIFY :,CREATE new.txt:,DELETE old.txt:,MODIFY sub:,CREATE sub/c.txt:,MODIFY sub/data.csv:,RENAME a.txt:sub/b.txt"},
+		{"s2", "s1", "MODIFY :,DELETE new.txt:,CREATE old.txt:,MODIFY sub:,DELETE sub/c.txt:,MODIFY sub/data.csv:,RENAME sub/b.txt:a.txt"},
+	}
+	for _, listing := range []bool{true, false} {
+		server := mockServerFor_SnapshotDiff(listing)
+		url, _ := url.Parse(server.URL)
+		fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+
+		for _, test := range tests {
+			entries, err := fs.GetSnapshotDiff(Path{Name: "/data"}, test.from, test.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var diff []string
+			for _, e := range entries {
+				diff = append(diff, fmt.Sprintf("%s %s:%s", e.Type, e.SourcePath, e.TargetPath))
+			}
+			if strings.Join(diff, ",") != test.expected {
+				t.Errorf("Expecting diff %s (listing=%v), but got %v", test.expected, listing, diff)
+			}
+		}
+		server.Close()
+	}
+}
+
+func Test_SnapshotDiff_Error(t *testing.T) {
+	server := mockServerFor_SnapshotDiff(true)
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	it := fs.SnapshotDiff(Path{Name: "/data"}, "s1", "missing")
+	if it.Next() {
+		t.Errorf("Expecting no entries, but got %v", it.Entry())
+	}
+	if it.Err() == nil {
+		t.Error("Expecting an error for a missing snapshot.")
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// Snapshottable /data holding snapshots s1 to s4, created 4 to 1 days ago
+// and listed out of order.
+func mockServerFor_Snapshots() *httptest.Server {
+	type snapshot struct {
+		id    int64
+		mtime int64
+	}
+	var mu sync.Mutex
+	now := time.Now()
+	nextID := int64(5)
+	snapshots := map[string]snapshot{}
+	order := []string{"s3", "s1", "s4", "s2"}
+	for i, days := range []int{2, 4, 1, 3} {
+		created := now.Add(-time.Duration(days) * 24 * time.Hour)
+		snapshots[order[i]] = snapshot{int64(5 - days), created.UnixNano() / 1e6}
+	}
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := req.URL.Query()
+		p := strings.TrimPrefix(req.URL.Path, WebHdfsVer)
+		if q.Get("op") == OP_GETSNAPSHOTTABLEDIRECTORYLIST {
+			if p != "/" {
+				log.Fatalf("Unexpected path %v", p)
+			}
+			fmt.Fprintf(rsp, `{"SnapshottableDirectoryList": [{"dirStatus": {"pathSuffix": "data", "type": "DIRECTORY"}, "parentFullPath": "/", "snapshotNumber": %d, "snapshotQuota": 65536}]}`, len(snapshots))
+			return
+		}
+		if p != "/data" {
+			log.Fatalf("Unexpected path %v", p)
+		}
+		missing := func(name string) {
+			rsp.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(rsp, `{"RemoteException": {"exception": "SnapshotException", "javaClassName": "org.apache.hadoop.hdfs.protocol.SnapshotException", "message": "Cannot find snapshot %s"}}`, name)
+		}
+		switch q.Get("op") {
+		case OP_ALLOWSNAPSHOT, OP_DISALLOWSNAPSHOT:
+			if req.Method != "PUT" {
+				log.Fatalf("Unexpected method %v", req.Method)
+			}
+		case OP_CREATESNAPSHOT:
+			name := q.Get("snapshotname")
+			if req.Method != "PUT" || name == "" {
+				log.Fatalf("Unexpected request [url=%v]", req.URL)
+			}
+			snapshots[name] = snapshot{nextID, time.Now().UnixNano() / 1e6}
+			order = append(order, name)
+			nextID++
+			fmt.Fprintf(rsp, `{"Path": "/data/.snapshot/%s"}`, name)
+		case OP_RENAMESNAPSHOT:
+			from, to := q.Get("oldsnapshotname"), q.Get("snapshotname")
+			s, ok := snapshots[from]
+			if !ok {
+				missing(from)
+				return
+			}
+			delete(snapshots, from)
+			snapshots[to] = s
+			for i := range order {
+				if order[i] == from {
+					order[i] = to
+				}
+			}
+		case OP_DELETESNAPSHOT:
+			name := q.Get("snapshotname")
+			if req.Method != "DELETE" {
+				log.Fatalf("Unexpected method %v", req.Method)
+			}
+			if _, ok := snapshots[name]; !ok {
+				missing(name)
+				return
+			}
+			delete(snapshots, name)
+		case OP_GETSNAPSHOTLIST:
+			var list []string
+			for _, name := range order {
+				if s, ok := snapshots[name]; ok {
+					list = append(list, fmt.Sprintf(`{"dirStatus": {"modificationTime": %d, "type": "DIRECTORY"}, "fullPath": "/data/.snapshot/%s", "snapshotID": %d}`, s.mtime, name, s.id))
+				}
+			}
+			fmt.Fprintf(rsp, `{"SnapshotList": [%s]}`, strings.Join(list, ","))
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+// Difference of /data from s1 to s2: new.txt created, old.txt deleted,
+// a.txt moved to sub/b.txt, sub/c.txt created and sub/data.csv modified.
+// /data has DirId 100, sub 101.  With listing, it is served in two pages
+// like a namenode would: created and deleted entries only carry the name
+// of the child, the second page resumes within /data, and the rename is
+// split between a deleted reference in /data and a created one in sub.
+// From s2 to s1, the same lists are served with isFromEarlier false.
+// Without listing, GETSNAPSHOTDIFFLISTING is rejected as by Hadoop before
+// 3.3.
+func mockServerFor_SnapshotDiff(listing bool) *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		if req.URL.Path != WebHdfsVer+"/data" {
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+		var fromEarlier bool
+		switch q.Get("oldsnapshotname") + ":" + q.Get("snapshotname") {
+		case "s1:s2":
+			fromEarlier = true
+		case "s2:s1":
+			fromEarlier = false
+		default:
+			rsp.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(rsp, `{"RemoteException": {"exception": "SnapshotException", "javaClassName": "org.apache.hadoop.hdfs.protocol.SnapshotException", "message": "Cannot find the snapshot of directory /data with name %s"}}`, q.Get("snapshotname"))
+			return
+		}
+		switch q.Get("op") {
+		case OP_GETSNAPSHOTDIFFLISTING:
+			if !listing {
+				rsp.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(rsp, `{"RemoteException": {"exception": "IllegalArgumentException", "javaClassName": "java.lang.IllegalArgumentException", "message": "Invalid value for webhdfs parameter \"op\": No enum constant org.apache.hadoop.hdfs.web.resources.GetOpParam.Op.GETSNAPSHOTDIFFLISTING"}}`)
+				return
+			}
+			switch start, index := q.Get("snapshotdiffstartpath"), q.Get("snapshotdiffindex"); {
+			case start == "" && index == "-1":
+				fmt.Fprintf(rsp, `{"SnapshotDiffReportListing": {
+					"createList": [{"dirId": 100, "fileId": 110, "isReference": false, "sourcePath": "new.txt"}],
+					"deleteList": [],
+					"modifyList": [{"dirId": 100, "fileId": 100, "isReference": true, "sourcePath": ""}],
+					"isFromEarlier": %v, "lastIndex": 1, "lastPath": ""}}`, fromEarlier)
+			case start == "" && index == "1":
+				fmt.Fprintf(rsp, `{"SnapshotDiffReportListing": {
+					"createList": [{"dirId": 101, "fileId": 112, "isReference": true, "sourcePath": "b.txt"},
+						{"dirId": 101, "fileId": 113, "isReference": false, "sourcePath": "c.txt"}],
+					"deleteList": [{"dirId": 100, "fileId": 112, "isReference": true, "sourcePath": "a.txt", "targetPath": "sub/b.txt"},
+						{"dirId": 100, "fileId": 111, "isReference": false, "sourcePath": "old.txt"}],
+					"modifyList": [{"dirId": 101, "fileId": 101, "isReference": true, "sourcePath": "sub"},
+						{"dirId": 101, "fileId": 114, "isReference": false, "sourcePath": "sub/data.csv"}],
+					"isFromEarlier": %v, "lastIndex": -1, "lastPath": ""}}`, fromEarlier)
+			default:
+				log.Fatalf("Unexpected page [url=%v]", req.URL)
+			}
+		case OP_GETSNAPSHOTDIFF:
+			if listing {
+				log.Fatalf("Unexpected request [url=%v]", req.URL)
+			}
+			created, deleted, source, target := "CREATE", "DELETE", "a.txt", "sub/b.txt"
+			if !fromEarlier {
+				created, deleted, source, target = deleted, created, target, source
+			}
+			fmt.Fprintf(rsp, `{"SnapshotDiffReport": {"diffList": [
+				{"sourcePath": "", "type": "MODIFY"},
+				{"sourcePath": "new.txt", "type": "%s"},
+				{"sourcePath": "old.txt", "type": "%s"},
+				{"sourcePath": "sub", "type": "MODIFY"},
+				{"sourcePath": "sub/c.txt", "type": "%s"},
+				{"sourcePath": "sub/data.csv", "type": "MODIFY"},
+				{"sourcePath": "%s", "targetPath": "%s", "type": "RENAME"}],
+				"fromSnapshot": "%s", "snapshotRoot": "/data", "toSnapshot": "%s"}}`,
+				created, deleted, created, source, target, q.Get("oldsnapshotname"), q.Get("snapshotname"))
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
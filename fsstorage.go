@@ -0,0 +1,129 @@
+package gowfs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Names of the built-in HDFS storage policies.
+const (
+	StoragePolicyHot         = "HOT"
+	StoragePolicyWarm        = "WARM"
+	StoragePolicyCold        = "COLD"
+	StoragePolicyAllSSD      = "ALL_SSD"
+	StoragePolicyOneSSD      = "ONE_SSD"
+	StoragePolicyLazyPersist = "LAZY_PERSIST"
+	StoragePolicyProvided    = "PROVIDED"
+)
+
+// Storage types of datanode volumes, see BlockStoragePolicy.StorageTypes
+// and SetQuotaByStorageType().
+const (
+	StorageTypeRamDisk  = "RAM_DISK"
+	StorageTypeSSD      = "SSD"
+	StorageTypeDisk     = "DISK"
+	StorageTypeArchive  = "ARCHIVE"
+	StorageTypeProvided = "PROVIDED"
+	StorageTypeNVDIMM   = "NVDIMM"
+)
+
+// Returns the storage policies supported by the cluster.
+// See HDFS FileSystem.getAllStoragePolicies()
+func (fs *FileSystem) GetAllStoragePolicies() ([]BlockStoragePolicy, error) {
+	return fs.GetAllStoragePoliciesContext(context.Background())
+}
+
+// GetAllStoragePolicies() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetAllStoragePoliciesContext(ctx context.Context) ([]BlockStoragePolicy, error) {
+	params := map[string]string{"op": OP_GETALLSTORAGEPOLICY}
+	u, err := fs.requestUrl(ctx, &Path{Name: "/"}, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return nil, err
+	}
+
+	return hdfsData.StoragePolicies.BlockStoragePolicy, nil
+}
+
+// Returns the storage policy of the specified path, inherited from its
+// closest ancestor when it has none.
+// See HDFS FileSystem.getStoragePolicy()
+func (fs *FileSystem) GetStoragePolicy(p Path) (BlockStoragePolicy, error) {
+	return fs.GetStoragePolicyContext(context.Background(), p)
+}
+
+// GetStoragePolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetStoragePolicyContext(ctx context.Context, p Path) (BlockStoragePolicy, error) {
+	params := map[string]string{"op": OP_GETSTORAGEPOLICY}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return BlockStoragePolicy{}, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return BlockStoragePolicy{}, err
+	}
+
+	return hdfsData.StoragePolicy, nil
+}
+
+// Sets the storage policy (i.e. StoragePolicyCold) of the specified path.
+// Existing blocks are only moved by the mover or SatisfyStoragePolicy().
+// See HDFS FileSystem.setStoragePolicy()
+func (fs *FileSystem) SetStoragePolicy(p Path, policy string) (bool, error) {
+	return fs.SetStoragePolicyContext(context.Background(), p, policy)
+}
+
+// SetStoragePolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetStoragePolicyContext(ctx context.Context, p Path, policy string) (bool, error) {
+	if policy == "" {
+		return false, fmt.Errorf("SetStoragePolicy() - param policy cannot be empty.")
+	}
+	params := map[string]string{"op": OP_SETSTORAGEPOLICY, "storagepolicy": policy}
+	return fs.putOperation(ctx, p, params)
+}
+
+// Removes the storage policy of the specified path, which then inherits
+// the policy of its parent.
+// See HDFS FileSystem.unsetStoragePolicy()
+func (fs *FileSystem) UnsetStoragePolicy(p Path) (bool, error) {
+	return fs.UnsetStoragePolicyContext(context.Background(), p)
+}
+
+// UnsetStoragePolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) UnsetStoragePolicyContext(ctx context.Context, p Path) (bool, error) {
+	params := map[string]string{"op": OP_UNSETSTORAGEPOLICY}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return false, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", u.String(), nil)
+	_, err = requestHdfsData(fs.client, *req)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Schedules the blocks of the specified path to be moved to the storage
+// types of its policy.  Requires the storage policy satisfier to be
+// enabled on the namenode.
+// See HDFS FileSystem.satisfyStoragePolicy()
+func (fs *FileSystem) SatisfyStoragePolicy(p Path) (bool, error) {
+	return fs.SatisfyStoragePolicyContext(context.Background(), p)
+}
+
+// SatisfyStoragePolicy() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SatisfyStoragePolicyContext(ctx context.Context, p Path) (bool, error) {
+	return fs.putOperation(ctx, p, map[string]string{"op": OP_SATISFYSTORAGEPOLICY})
+}
@@ -0,0 +1,113 @@
+package gowfs
+
+import "fmt"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "strings"
+import "sync"
+import "testing"
+
+func Test_StoragePolicy(t *testing.T) {
+	server := mockServerFor_StoragePolicy()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	p := Path{Name: "/data/old"}
+
+	policies, err := fs.GetAllStoragePolicies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 2 || policies[1].Name != StoragePolicyCold || policies[1].StorageTypes[0] != "ARCHIVE" {
+		t.Errorf("Expecting HOT and COLD policies, but got %v", policies)
+	}
+
+	if _, err := fs.SetStoragePolicy(p, StoragePolicyCold); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := fs.GetStoragePolicy(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Name != StoragePolicyCold || policy.Id != 2 {
+		t.Errorf("Expecting the COLD policy, but got %v", policy)
+	}
+	stat, err := fs.GetFileStatus(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.StoragePolicy != 2 {
+		t.Errorf("Expecting storagePolicy 2 in FileStatus, but got %d", stat.StoragePolicy)
+	}
+
+	if _, err := fs.SatisfyStoragePolicy(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.UnsetStoragePolicy(p); err != nil {
+		t.Fatal(err)
+	}
+	if policy, _ := fs.GetStoragePolicy(p); policy.Name != StoragePolicyHot {
+		t.Errorf("Expecting the inherited HOT policy, but got %v", policy)
+	}
+	if _, err := fs.SetStoragePolicy(p, ""); err == nil {
+		t.Error("Expecting an error for an empty policy.")
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// Cluster with the HOT (default) and COLD policies, /data/old starts
+// without a policy.
+func mockServerFor_StoragePolicy() *httptest.Server {
+	var mu sync.Mutex
+	policies := map[string]string{
+		"HOT":  `{"copyOnCreateFile": false, "creationFallbacks": [], "id": 7, "name": "HOT", "replicationFallbacks": ["ARCHIVE"], "storageTypes": ["DISK"]}`,
+		"COLD": `{"copyOnCreateFile": false, "creationFallbacks": [], "id": 2, "name": "COLD", "replicationFallbacks": [], "storageTypes": ["ARCHIVE"]}`,
+	}
+	ids := map[string]int{"": 0, "HOT": 7, "COLD": 2}
+	current := ""
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := req.URL.Query()
+		p := strings.TrimPrefix(req.URL.Path, WebHdfsVer)
+		if q.Get("op") == OP_GETALLSTORAGEPOLICY {
+			fmt.Fprintf(rsp, `{"BlockStoragePolicies": {"BlockStoragePolicy": [%s, %s]}}`, policies["HOT"], policies["COLD"])
+			return
+		}
+		if p != "/data/old" {
+			log.Fatalf("Unexpected path %v", p)
+		}
+		switch q.Get("op") {
+		case OP_GETSTORAGEPOLICY:
+			name := current
+			if name == "" {
+				name = "HOT"
+			}
+			fmt.Fprintf(rsp, `{"BlockStoragePolicy": %s}`, policies[name])
+		case OP_GETFILESTATUS:
+			fmt.Fprintf(rsp, `{"FileStatus": {"pathSuffix": "", "storagePolicy": %d, "type": "DIRECTORY"}}`, ids[current])
+		case OP_SETSTORAGEPOLICY:
+			if _, ok := policies[q.Get("storagepolicy")]; !ok || req.Method != "PUT" {
+				log.Fatalf("Unexpected request [url=%v]", req.URL)
+			}
+			current = q.Get("storagepolicy")
+		case OP_UNSETSTORAGEPOLICY:
+			if req.Method != "POST" {
+				log.Fatalf("Unexpected method %v", req.Method)
+			}
+			current = ""
+		case OP_SATISFYSTORAGEPOLICY:
+			if req.Method != "PUT" {
+				log.Fatalf("Unexpected method %v", req.Method)
+			}
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
@@ -1,17 +1,32 @@
 package gowfs
 
+import "context"
 import "fmt"
 import "net/http"
 
+// Returns the configuration used for token operations.  Like the Hadoop
+// client, token operations never authenticate with a delegation token.
+func (fs *FileSystem) tokenConfig() Configuration {
+	conf := fs.Config
+	conf.DelegationToken = ""
+	conf.TokenSource = nil
+	return conf
+}
+
 func (fs *FileSystem) GetDelegationToken(renewer string) (Token, error) {
+	return fs.GetDelegationTokenContext(context.Background(), renewer)
+}
+
+// GetDelegationToken() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetDelegationTokenContext(ctx context.Context, renewer string) (Token, error) {
 	params := map[string]string{"op": OP_GETDELEGATIONTOKEN, "renewer": renewer}
 
-	u, err := buildRequestUrl(fs.Config, nil, &params)
+	u, err := buildRequestUrl(fs.tokenConfig(), nil, &params)
 	if err != nil {
 		return Token{}, err
 	}
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return Token{}, err
@@ -21,14 +36,19 @@ func (fs *FileSystem) GetDelegationToken(renewer string) (Token, error) {
 }
 
 func (fs *FileSystem) GetDelegationTokens(renewer string) ([]Token, error) {
+	return fs.GetDelegationTokensContext(context.Background(), renewer)
+}
+
+// GetDelegationTokens() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetDelegationTokensContext(ctx context.Context, renewer string) ([]Token, error) {
 	params := map[string]string{"op": OP_GETDELEGATIONTOKENS, "renewer": renewer}
 
-	u, err := buildRequestUrl(fs.Config, nil, &params)
+	u, err := buildRequestUrl(fs.tokenConfig(), nil, &params)
 	if err != nil {
 		return nil, err
 	}
 
-	req, _ := http.NewRequest("GET", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return nil, err
@@ -38,14 +58,19 @@ func (fs *FileSystem) GetDelegationTokens(renewer string) ([]Token, error) {
 }
 
 func (fs *FileSystem) RenewDelegationToken(token string) (int64, error) {
+	return fs.RenewDelegationTokenContext(context.Background(), token)
+}
+
+// RenewDelegationToken() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) RenewDelegationTokenContext(ctx context.Context, token string) (int64, error) {
 	params := map[string]string{"op": OP_RENEWDELEGATIONTOKEN, "token": token}
 
-	u, err := buildRequestUrl(fs.Config, nil, &params)
+	u, err := buildRequestUrl(fs.tokenConfig(), nil, &params)
 	if err != nil {
 		return -1, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	hdfsData, err := requestHdfsData(fs.client, *req)
 	if err != nil {
 		return -1, err
@@ -56,14 +81,19 @@ func (fs *FileSystem) RenewDelegationToken(token string) (int64, error) {
 }
 
 func (fs *FileSystem) CancelDelegationToken(token string) (bool, error) {
+	return fs.CancelDelegationTokenContext(context.Background(), token)
+}
+
+// CancelDelegationToken() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) CancelDelegationTokenContext(ctx context.Context, token string) (bool, error) {
 	params := map[string]string{"op": OP_CANCELDELEGATIONTOKEN, "token": token}
 
-	u, err := buildRequestUrl(fs.Config, nil, &params)
+	u, err := buildRequestUrl(fs.tokenConfig(), nil, &params)
 	if err != nil {
 		return false, err
 	}
 
-	req, _ := http.NewRequest("PUT", u.String(), nil)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
 	rsp, err := fs.client.Do(req)
 	if err != nil {
 		return false, err
@@ -6,6 +6,7 @@ import "net/url"
 import "net/http"
 import "net/http/httptest"
 import _ "strconv"
+import "bytes"
 
 import "testing"
 
@@ -85,6 +86,29 @@ func Test_CancelDelegationTokens(t *testing.T) {
 	}
 }
 
+func Test_WithToken(t *testing.T) {
+	server1 := mockServerFor_DelegatedWrite()
+	servUrl, _ := url.Parse(server1.URL)
+	server2 := mockServerFor_DelegatedCreate(servUrl)
+	defer server2.Close()
+	defer server1.Close()
+	t.Logf("Test_WithToken - Started httptest.Server on %v", server2.URL)
+
+	url, _ := url.Parse(server2.URL)
+	conf := Configuration{Addr: url.Host, User: "hdfsuser"}
+	fs, _ := NewFileSystem(conf)
+	tfs := fs.WithToken(Token{UrlString: "JQAIaG9y1afae2radfaerzcqdt14AfeE="})
+
+	if fs.Config.DelegationToken != "" {
+		t.Fatal("WithToken() - original FileSystem should not be modified.")
+	}
+
+	_, err := tfs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // *********************** Mock Servers ********************* //
 const getDelegationTokenRsp = `
 {
@@ -187,3 +211,35 @@ func mockServerFor_CancelDelegationToken() *httptest.Server {
 	}
 	return httptest.NewServer(http.HandlerFunc(handler))
 }
+
+func checkDelegationParams(req *http.Request) {
+	q := req.URL.Query()
+	if q.Get("delegation") != "JQAIaG9y1afae2radfaerzcqdt14AfeE=" {
+		log.Fatalf("Expected param delegation, but was %v", q.Get("delegation"))
+	}
+	if q.Get("user.name") != "" {
+		log.Fatalf("Expected no user.name param, but was %v", q.Get("user.name"))
+	}
+}
+
+func mockServerFor_DelegatedCreate(redir *url.URL) *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		checkDelegationParams(req)
+
+		// redirect without delegation param, the client must attach it.
+		rsp.Header().Set("Location", redir.Scheme+"://"+redir.Host+req.URL.Path+"?op=CREATE")
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func mockServerFor_DelegatedWrite() *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		if req.Method != "PUT" {
+			log.Fatalf("Expecting Request.Method PUT, but got %v", req.Method)
+		}
+		checkDelegationParams(req)
+		rsp.WriteHeader(http.StatusCreated)
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
@@ -0,0 +1,150 @@
+package gowfs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Encoding of xattr values returned by GetXAttrs().
+type XAttrEncoding string
+
+const (
+	XAttrEncodingText   XAttrEncoding = "TEXT"
+	XAttrEncodingHex    XAttrEncoding = "HEX"
+	XAttrEncodingBase64 XAttrEncoding = "BASE64"
+)
+
+// Flags for SetXAttr().
+type XAttrFlag string
+
+const (
+	XAttrCreate  XAttrFlag = "CREATE"  // fails if the xattr exists
+	XAttrReplace XAttrFlag = "REPLACE" // fails if the xattr does not exist
+)
+
+// Returns the decoded value of the xattr.
+func (x XAttr) Bytes() ([]byte, error) {
+	v := x.Value
+	switch {
+	case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+		return []byte(v[1 : len(v)-1]), nil
+	case strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X"):
+		return hex.DecodeString(v[2:])
+	case strings.HasPrefix(v, "0s") || strings.HasPrefix(v, "0S"):
+		return base64.StdEncoding.DecodeString(v[2:])
+	}
+	return []byte(v), nil
+}
+
+// Sets the xattr name (i.e. user.schema) of the specified path.  Without
+// flags, the xattr is created or replaced.
+// See HDFS FileSystem.setXAttr()
+func (fs *FileSystem) SetXAttr(p Path, name string, value []byte, flags ...XAttrFlag) (bool, error) {
+	return fs.SetXAttrContext(context.Background(), p, name, value, flags...)
+}
+
+// SetXAttr() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) SetXAttrContext(ctx context.Context, p Path, name string, value []byte, flags ...XAttrFlag) (bool, error) {
+	if name == "" {
+		return false, fmt.Errorf("SetXAttr() - param name cannot be empty.")
+	}
+	if len(flags) == 0 {
+		flags = []XAttrFlag{XAttrCreate, XAttrReplace}
+	}
+	flagNames := make([]string, len(flags))
+	for i, flag := range flags {
+		flagNames[i] = string(flag)
+	}
+
+	params := map[string]string{"op": OP_SETXATTR}
+	params["xattr.name"] = name
+	// base64 encoded so any value is sent as is.
+	params["xattr.value"] = "0s" + base64.StdEncoding.EncodeToString(value)
+	params["flag"] = strings.Join(flagNames, ",")
+
+	return fs.putOperation(ctx, p, params)
+}
+
+// Removes the xattr name of the specified path.
+// See HDFS FileSystem.removeXAttr()
+func (fs *FileSystem) RemoveXAttr(p Path, name string) (bool, error) {
+	return fs.RemoveXAttrContext(context.Background(), p, name)
+}
+
+// RemoveXAttr() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) RemoveXAttrContext(ctx context.Context, p Path, name string) (bool, error) {
+	if name == "" {
+		return false, fmt.Errorf("RemoveXAttr() - param name cannot be empty.")
+	}
+	params := map[string]string{"op": OP_REMOVEXATTR, "xattr.name": name}
+	return fs.putOperation(ctx, p, params)
+}
+
+// Returns the specified xattrs of the path, or all of them when no names
+// are provided.  Values are encoded with encoding (text when empty).
+// See HDFS FileSystem.getXAttrs()
+func (fs *FileSystem) GetXAttrs(p Path, encoding XAttrEncoding, names ...string) ([]XAttr, error) {
+	return fs.GetXAttrsContext(context.Background(), p, encoding, names...)
+}
+
+// GetXAttrs() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) GetXAttrsContext(ctx context.Context, p Path, encoding XAttrEncoding, names ...string) ([]XAttr, error) {
+	if encoding == "" {
+		encoding = XAttrEncodingText
+	}
+	params := map[string]string{"op": OP_GETXATTRS, "encoding": string(encoding)}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return nil, err
+	}
+	// xattr.name is repeated for each name.
+	q := u.Query()
+	for _, name := range names {
+		q.Add("xattr.name", name)
+	}
+	u.RawQuery = q.Encode()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return nil, err
+	}
+
+	return hdfsData.XAttrs, nil
+}
+
+// Returns the names of the xattrs of the specified path.
+// See HDFS FileSystem.listXAttrs()
+func (fs *FileSystem) ListXAttrs(p Path) ([]string, error) {
+	return fs.ListXAttrsContext(context.Background(), p)
+}
+
+// ListXAttrs() with a context.Context to cancel the request or set a deadline.
+func (fs *FileSystem) ListXAttrsContext(ctx context.Context, p Path) ([]string, error) {
+	params := map[string]string{"op": OP_LISTXATTRS}
+	u, err := fs.requestUrl(ctx, &p, &params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	hdfsData, err := requestHdfsData(fs.client, *req)
+	if err != nil {
+		return nil, err
+	}
+
+	// the names are a JSON array encoded as a string.
+	var names []string
+	if hdfsData.XAttrNames != "" {
+		if err := json.Unmarshal([]byte(hdfsData.XAttrNames), &names); err != nil {
+			return nil, fmt.Errorf("ListXAttrs(%s) - invalid XAttrNames %s", p.Name, strconv.Quote(hdfsData.XAttrNames))
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,173 @@
+package gowfs
+
+import "encoding/base64"
+import "encoding/json"
+import "fmt"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "sort"
+import "strings"
+import "sync"
+import "testing"
+
+func Test_XAttrs(t *testing.T) {
+	server := mockServerFor_XAttrs()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, User: "hdfs"})
+	p := Path{Name: "/data/a.txt"}
+
+	if _, err := fs.SetXAttr(p, "user.schema", []byte("v2"), XAttrCreate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.SetXAttr(p, "user.schema", []byte("v3"), XAttrCreate); err == nil {
+		t.Error("Expecting an error creating an existing xattr.")
+	}
+	if _, err := fs.SetXAttr(p, "user.job", []byte{0, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := fs.ListXAttrs(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "user.job,user.schema" {
+		t.Errorf("Expecting xattrs user.job and user.schema, but got %v", names)
+	}
+
+	xattrs, err := fs.GetXAttrs(p, XAttrEncodingText, "user.schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(xattrs) != 1 || xattrs[0].Value != `"v2"` {
+		t.Errorf("Expecting user.schema=\"v2\", but got %v", xattrs)
+	}
+	xattrs, err = fs.GetXAttrs(p, XAttrEncodingHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(xattrs) != 2 {
+		t.Fatalf("Expecting 2 xattrs, but got %v", xattrs)
+	}
+	for _, xattr := range xattrs {
+		value, err := xattr.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if xattr.Name == "user.job" && string(value) != "\x00\x01\x02" {
+			t.Errorf("Expecting user.job value 000102, but got %v", xattr.Value)
+		}
+	}
+
+	if _, err := fs.RemoveXAttr(p, "user.job"); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := fs.ListXAttrs(p); len(names) != 1 {
+		t.Errorf("Expecting user.job removed, but got %v", names)
+	}
+}
+
+func Test_XAttrBytes(t *testing.T) {
+	for value, expected := range map[string]string{
+		`"v2"`:   "v2",
+		"0x7632": "v2",
+		"0sdjI=": "v2",
+	} {
+		b, err := XAttr{Name: "user.schema", Value: value}.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected {
+			t.Errorf("Expecting %s decoded to %s, but got %s", value, expected, string(b))
+		}
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// In-memory namespace with xattrs: /data holds a.txt and sub/b.txt.
+func mockServerFor_XAttrs() *httptest.Server {
+	var mu sync.Mutex
+	dirs := map[string][]string{
+		"/data":     {"a.txt", "sub"},
+		"/data/sub": {"b.txt"},
+	}
+	xattrs := map[string]map[string][]byte{
+		"/data": {}, "/data/a.txt": {}, "/data/sub": {}, "/data/sub/b.txt": {},
+	}
+	status := func(name string) string {
+		typ := "FILE"
+		if _, ok := dirs[name]; ok {
+			typ = "DIRECTORY"
+		}
+		return fmt.Sprintf(`{"pathSuffix": "%s", "type": "%s"}`, name[strings.LastIndex(name, "/")+1:], typ)
+	}
+	encode := func(value []byte, encoding string) string {
+		switch encoding {
+		case "HEX":
+			return fmt.Sprintf("0x%x", value)
+		case "BASE64":
+			return "0s" + base64.StdEncoding.EncodeToString(value)
+		}
+		return `"` + string(value) + `"`
+	}
+
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		p := strings.TrimPrefix(req.URL.Path, WebHdfsVer)
+		attrs, ok := xattrs[p]
+		if !ok {
+			log.Fatalf("Unexpected path %v", p)
+		}
+		q := req.URL.Query()
+		name := q.Get("xattr.name")
+		switch q.Get("op") {
+		case OP_GETFILESTATUS:
+			fmt.Fprintf(rsp, `{"FileStatus": %s}`, status(p))
+		case OP_LISTSTATUS:
+			var statuses []string
+			for _, child := range dirs[p] {
+				statuses = append(statuses, status(p+"/"+child))
+			}
+			fmt.Fprintf(rsp, `{"FileStatuses": {"FileStatus": [%s]}}`, strings.Join(statuses, ","))
+		case OP_SETXATTR:
+			value := q.Get("xattr.value")
+			if !strings.HasPrefix(value, "0s") {
+				log.Fatalf("Expecting a base64 xattr.value, but got %v", value)
+			}
+			_, exists := attrs[name]
+			flags := q.Get("flag")
+			if (exists && !strings.Contains(flags, "REPLACE")) || (!exists && !strings.Contains(flags, "CREATE")) {
+				rsp.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(rsp, `{"RemoteException": {"exception": "IOException", "message": "XAttr flag mismatch"}}`)
+				return
+			}
+			attrs[name], _ = base64.StdEncoding.DecodeString(value[2:])
+		case OP_REMOVEXATTR:
+			delete(attrs, name)
+		case OP_GETXATTRS:
+			var entries []string
+			for n, v := range attrs {
+				if names := q["xattr.name"]; len(names) == 0 || strings.Contains(strings.Join(names, ","), n) {
+					entries = append(entries, fmt.Sprintf(`{"name": "%s", "value": %q}`, n, encode(v, q.Get("encoding"))))
+				}
+			}
+			fmt.Fprintf(rsp, `{"XAttrs": [%s]}`, strings.Join(entries, ","))
+		case OP_LISTXATTRS:
+			var names []string
+			for n := range attrs {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			encoded, _ := json.Marshal(names)
+			fmt.Fprintf(rsp, `{"XAttrNames": %q}`, string(encoded))
+		default:
+			log.Fatalf("Unexpected request [url=%v]", req.URL)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
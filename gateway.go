@@ -0,0 +1,24 @@
+package gowfs
+
+import "net/http"
+
+// Sets HTTP Basic credentials on requests sent to the gateway (or
+// namenode) hosts.  Credentials are not sent to other hosts, such as
+// datanodes that are reached directly.
+type basicAuthTransport struct {
+	user     string
+	password string
+	hosts    []string
+	next     http.RoundTripper
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for _, host := range t.hosts {
+		if req.URL.Host == host {
+			r := cloneRequest(req)
+			r.SetBasicAuth(t.user, t.password)
+			return t.next.RoundTrip(r)
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,100 @@
+package gowfs
+
+import "bytes"
+import "io/ioutil"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "testing"
+
+func Test_Gateway(t *testing.T) {
+	server := mockServerFor_Gateway()
+	defer server.Close()
+	t.Logf("Test_Gateway - Started httptest.Server on %v", server.URL)
+
+	conf := Configuration{
+		Endpoint:          server.URL + "/gateway/default",
+		BasicAuthUser:     "guest",
+		BasicAuthPassword: "guest-password",
+	}
+	fs, _ := NewFileSystem(conf)
+
+	stats, err := fs.ListStatus(Path{Name: "/test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Errorf("Expecting 2 items, but got %d", len(stats))
+	}
+
+	_, err = fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Gateway_DirectDatanode(t *testing.T) {
+	datanode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if _, _, ok := req.BasicAuth(); ok {
+			log.Fatalf("Basic credentials must not be sent to datanodes [url=%v]", req.URL)
+		}
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer datanode.Close()
+	namenode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if _, _, ok := req.BasicAuth(); !ok {
+			log.Fatalf("Expecting Basic credentials [url=%v]", req.URL)
+		}
+		rsp.Header().Set("Location", datanode.URL+req.URL.RequestURI())
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer namenode.Close()
+
+	fs, _ := NewFileSystem(Configuration{Endpoint: namenode.URL, BasicAuthUser: "guest", BasicAuthPassword: "guest-password"})
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// Knox-like gateway: webhdfs requests are served under a topology prefix
+// and writes are redirected to a data path on the gateway itself.
+func mockServerFor_Gateway() *httptest.Server {
+	const prefix = "/gateway/default"
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		if user, password, ok := req.BasicAuth(); !ok || user != "guest" || password != "guest-password" {
+			rsp.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.URL.Query().Get("user.name") != "" {
+			log.Fatalf("Expecting no user.name param, but got [url=%v]", req.URL)
+		}
+		switch req.URL.Path {
+		case prefix + "/webhdfs/v1/test":
+			if req.URL.Query().Get("op") != OP_LISTSTATUS {
+				log.Fatalf("Expecting op=LISTSTATUS, but got [url=%v]", req.URL)
+			}
+			rsp.Write([]byte(listStatusRsp))
+		case prefix + "/webhdfs/v1/testing/newfile":
+			if req.URL.Query().Get("op") != OP_CREATE {
+				log.Fatalf("Expecting op=CREATE, but got [url=%v]", req.URL)
+			}
+			rsp.Header().Set("Location", prefix+"/webhdfs/data/v1/webhdfs/v1/testing/newfile?_=AAAACAAAABAAAAEA")
+			rsp.WriteHeader(http.StatusTemporaryRedirect)
+		case prefix + "/webhdfs/data/v1/webhdfs/v1/testing/newfile":
+			if req.URL.Query().Get("_") != "AAAACAAAABAAAAEA" {
+				log.Fatalf("Expecting the gateway redirect query, but got [url=%v]", req.URL)
+			}
+			data, _ := ioutil.ReadAll(req.Body)
+			if string(data) != "Hello webhdfs users!" {
+				log.Fatalf("Expected data not posted to server. Server got %v", string(data))
+			}
+			rsp.WriteHeader(http.StatusCreated)
+		default:
+			log.Fatalf("Unexpected gateway path %v", req.URL.Path)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
@@ -0,0 +1,198 @@
+package gowfs
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Default namenode web addresses, from hdfs-default.xml (ver 3).  Hadoop 2
+// clusters (50070/50470) must set dfs.namenode.http(s)-address.
+const (
+	defaultNameNodeHttpAddr  = "0.0.0.0:9870"
+	defaultNameNodeHttpsAddr = "0.0.0.0:9871"
+)
+
+// Properties loaded from Hadoop XML configuration files
+// (core-site.xml, hdfs-site.xml).
+type HadoopConfig map[string]string
+
+type xmlConfiguration struct {
+	Properties []struct {
+		Name  string `xml:"name"`
+		Value string `xml:"value"`
+	} `xml:"property"`
+}
+
+// Loads properties from the specified Hadoop XML files.  Properties in
+// later files override properties in earlier files.
+func LoadHadoopConfig(files ...string) (HadoopConfig, error) {
+	hc := HadoopConfig{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		var doc xmlConfiguration
+		err = xml.NewDecoder(f).Decode(&doc)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("LoadHadoopConfig() - unable to parse %s: %s", file, err.Error())
+		}
+		for _, prop := range doc.Properties {
+			hc[strings.TrimSpace(prop.Name)] = strings.TrimSpace(prop.Value)
+		}
+	}
+	return hc, nil
+}
+
+// Loads core-site.xml and hdfs-site.xml from the specified directory.
+// When dir is empty, the HADOOP_CONF_DIR environment variable is used.
+// Missing files are skipped.
+func LoadHadoopConfigDir(dir string) (HadoopConfig, error) {
+	if dir == "" {
+		dir = os.Getenv("HADOOP_CONF_DIR")
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("LoadHadoopConfigDir() - directory not provided and HADOOP_CONF_DIR not set.")
+	}
+	var files []string
+	for _, name := range []string{"core-site.xml", "hdfs-site.xml"} {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("LoadHadoopConfigDir() - no configuration files found in %s", dir)
+	}
+	return LoadHadoopConfig(files...)
+}
+
+var hadoopVarRegexp = regexp.MustCompile(`\$\{([^}$ ]+)\}`)
+
+// Returns the value of the property, with ${var} references expanded from
+// other properties or from the environment.
+func (hc HadoopConfig) Get(name string) string {
+	val := hc[name]
+	for i := 0; i < 20 && strings.Contains(val, "${"); i++ {
+		val = hadoopVarRegexp.ReplaceAllStringFunc(val, func(ref string) string {
+			key := ref[2 : len(ref)-1]
+			if v, ok := hc[key]; ok {
+				return v
+			}
+			if v, ok := os.LookupEnv(strings.TrimPrefix(key, "env.")); ok {
+				return v
+			}
+			return ref
+		})
+	}
+	return val
+}
+
+// Returns true when hadoop.security.authentication is kerberos.  Such
+// clusters require Configuration.Authenticator (i.e. SpnegoAuthenticator).
+func (hc HadoopConfig) KerberosEnabled() bool {
+	return strings.EqualFold(hc.Get("hadoop.security.authentication"), "kerberos")
+}
+
+// Builds a Configuration from the properties.  The namenode(s) are
+// resolved from fs.defaultFS, using dfs.nameservices and
+// dfs.ha.namenodes.* when it names an HA nameservice.  The https
+// addresses are used when dfs.http.policy is HTTPS_ONLY.
+func (hc HadoopConfig) Configuration() (*Configuration, error) {
+	if strings.EqualFold(hc.Get("dfs.webhdfs.enabled"), "false") {
+		return nil, fmt.Errorf("HadoopConfig - WebHDFS is disabled (dfs.webhdfs.enabled=false).")
+	}
+
+	defaultFS := hc.Get("fs.defaultFS")
+	if defaultFS == "" {
+		defaultFS = hc.Get("fs.default.name") // deprecated name
+	}
+	if defaultFS == "" {
+		return nil, fmt.Errorf("HadoopConfig - fs.defaultFS not set.")
+	}
+	fsUrl, err := url.Parse(defaultFS)
+	if err != nil {
+		return nil, fmt.Errorf("HadoopConfig - invalid fs.defaultFS %s: %s", defaultFS, err.Error())
+	}
+
+	conf := NewConfiguration()
+	conf.UseTLS = strings.EqualFold(hc.Get("dfs.http.policy"), "HTTPS_ONLY")
+	addrKey, defaultAddr := "dfs.namenode.http-address", defaultNameNodeHttpAddr
+	if conf.UseTLS {
+		addrKey, defaultAddr = "dfs.namenode.https-address", defaultNameNodeHttpsAddr
+	}
+
+	nameservice := fsUrl.Hostname()
+	if hc.isNameService(nameservice) {
+		ids := splitList(hc.Get("dfs.ha.namenodes." + nameservice))
+		var addrs []string
+		for _, id := range ids {
+			if addr := hc.Get(addrKey + "." + nameservice + "." + id); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+		if len(addrs) == 0 {
+			if addr := hc.Get(addrKey + "." + nameservice); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("HadoopConfig - no %s found for nameservice %s", addrKey, nameservice)
+		}
+		conf.Addr, conf.Addrs = addrs[0], addrs[1:]
+	} else {
+		addr := hc.Get(addrKey)
+		if addr == "" {
+			addr = defaultAddr
+		}
+		conf.Addr = resolveWildcardAddr(addr, fsUrl.Hostname())
+	}
+
+	if !hc.KerberosEnabled() {
+		conf.User = hc.Get("hadoop.http.staticuser.user")
+	}
+	return conf, nil
+}
+
+func (hc HadoopConfig) isNameService(name string) bool {
+	for _, ns := range splitList(hc.Get("dfs.nameservices")) {
+		if ns == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Replaces a wildcard bind address (0.0.0.0:port) with host.
+func resolveWildcardAddr(addr, host string) string {
+	if strings.HasPrefix(addr, "0.0.0.0:") && host != "" {
+		return host + addr[len("0.0.0.0"):]
+	}
+	return addr
+}
+
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Builds a Configuration from core-site.xml and hdfs-site.xml found in
+// dir (or HADOOP_CONF_DIR when dir is empty).
+func LoadConfiguration(dir string) (*Configuration, error) {
+	hc, err := LoadHadoopConfigDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return hc.Configuration()
+}
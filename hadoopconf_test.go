@@ -0,0 +1,139 @@
+package gowfs
+
+import "io/ioutil"
+import "os"
+import "path/filepath"
+
+import "testing"
+
+const coreSiteXml = `<?xml version="1.0"?>
+<configuration>
+  <property>
+    <name>fs.defaultFS</name>
+    <value>hdfs://mycluster</value>
+  </property>
+  <property>
+    <name>hadoop.security.authentication</name>
+    <value>simple</value>
+  </property>
+  <property>
+    <name>hadoop.http.staticuser.user</name>
+    <value>hdfs</value>
+  </property>
+</configuration>
+`
+
+const hdfsSiteXml = `<?xml version="1.0"?>
+<configuration>
+  <property><name>dfs.nameservices</name><value>mycluster</value></property>
+  <property><name>dfs.ha.namenodes.mycluster</name><value>nn1, nn2</value></property>
+  <property><name>nn.domain</name><value>example.com</value></property>
+  <property><name>dfs.namenode.http-address.mycluster.nn1</name><value>nn1.${nn.domain}:50070</value></property>
+  <property><name>dfs.namenode.http-address.mycluster.nn2</name><value>nn2.${nn.domain}:50070</value></property>
+  <property><name>dfs.namenode.https-address.mycluster.nn1</name><value>nn1.${nn.domain}:50470</value></property>
+  <property><name>dfs.namenode.https-address.mycluster.nn2</name><value>nn2.${nn.domain}:50470</value></property>
+  <property><name>dfs.webhdfs.enabled</name><value>true</value></property>
+</configuration>
+`
+
+func writeHadoopConfDir(t *testing.T, core, hdfs string) string {
+	dir, err := ioutil.TempDir("", "gowfs-conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "core-site.xml"), []byte(core), 0644)
+	if hdfs != "" {
+		ioutil.WriteFile(filepath.Join(dir, "hdfs-site.xml"), []byte(hdfs), 0644)
+	}
+	return dir
+}
+
+func Test_LoadConfiguration_HA(t *testing.T) {
+	dir := writeHadoopConfDir(t, coreSiteXml, hdfsSiteXml)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("HADOOP_CONF_DIR", dir)
+	defer os.Unsetenv("HADOOP_CONF_DIR")
+
+	conf, err := LoadConfiguration("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Addr != "nn1.example.com:50070" {
+		t.Errorf("Expecting Addr nn1.example.com:50070, but got %s", conf.Addr)
+	}
+	if len(conf.Addrs) != 1 || conf.Addrs[0] != "nn2.example.com:50070" {
+		t.Errorf("Expecting Addrs [nn2.example.com:50070], but got %v", conf.Addrs)
+	}
+	if conf.User != "hdfs" {
+		t.Errorf("Expecting User hdfs, but got %s", conf.User)
+	}
+	if conf.UseTLS {
+		t.Error("Expecting UseTLS to be false.")
+	}
+}
+
+func Test_LoadConfiguration_HttpsAndKerberos(t *testing.T) {
+	core := `<configuration>
+  <property><name>fs.defaultFS</name><value>hdfs://namenode.example.com:8020</value></property>
+  <property><name>hadoop.security.authentication</name><value>kerberos</value></property>
+  <property><name>hadoop.http.staticuser.user</name><value>dr.who</value></property>
+</configuration>`
+	hdfs := `<configuration>
+  <property><name>dfs.http.policy</name><value>HTTPS_ONLY</value></property>
+</configuration>`
+	dir := writeHadoopConfDir(t, core, hdfs)
+	defer os.RemoveAll(dir)
+
+	hc, err := LoadHadoopConfigDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hc.KerberosEnabled() {
+		t.Error("Expecting KerberosEnabled() to be true.")
+	}
+	conf, err := hc.Configuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Addr != "namenode.example.com:9871" || !conf.UseTLS {
+		t.Errorf("Expecting https namenode.example.com:9871, but got %s (tls=%v)", conf.Addr, conf.UseTLS)
+	}
+	if conf.User != "" {
+		t.Errorf("Expecting no static user with kerberos, but got %s", conf.User)
+	}
+}
+
+func Test_LoadConfiguration_DefaultHttpAddr(t *testing.T) {
+	core := `<configuration>
+  <property><name>fs.defaultFS</name><value>hdfs://namenode.example.com:8020</value></property>
+</configuration>`
+	hdfs := `<configuration>
+  <property><name>dfs.replication</name><value>3</value></property>
+</configuration>`
+	dir := writeHadoopConfDir(t, core, hdfs)
+	defer os.RemoveAll(dir)
+
+	conf, err := LoadConfiguration(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Addr != "namenode.example.com:9870" || conf.UseTLS {
+		t.Errorf("Expecting http namenode.example.com:9870, but got %s (tls=%v)", conf.Addr, conf.UseTLS)
+	}
+}
+
+func Test_LoadConfiguration_WebHdfsDisabled(t *testing.T) {
+	core := `<configuration>
+  <property><name>fs.defaultFS</name><value>hdfs://namenode:8020</value></property>
+</configuration>`
+	hdfs := `<configuration>
+  <property><name>dfs.webhdfs.enabled</name><value>false</value></property>
+</configuration>`
+	dir := writeHadoopConfDir(t, core, hdfs)
+	defer os.RemoveAll(dir)
+
+	if _, err := LoadConfiguration(dir); err == nil {
+		t.Fatal("Expecting error when WebHDFS is disabled.")
+	}
+}
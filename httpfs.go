@@ -0,0 +1,54 @@
+package gowfs
+
+import (
+	"net/url"
+	"sync/atomic"
+)
+
+// Content type HttpFS requires for uploaded data.
+const HttpFSContentType = "application/octet-stream"
+
+// Flavor of the server implementing the WebHDFS REST API.
+type ServerFlavor string
+
+const (
+	// Detects HttpFS from its upload redirects (Location with data=true).
+	FlavorAuto ServerFlavor = ""
+	// NameNode WebHDFS: writes are redirected to a datanode.
+	FlavorWebHDFS ServerFlavor = "webhdfs"
+	// HttpFS: writes send data=true and their data in a single request.
+	FlavorHttpFS ServerFlavor = "httpfs"
+)
+
+// Returns true when writes should be sent the HttpFS way.
+func (fs *FileSystem) isHttpFS() bool {
+	switch fs.Config.Flavor {
+	case FlavorHttpFS:
+		return true
+	case FlavorAuto:
+		return fs.httpFS != nil && atomic.LoadInt32(fs.httpFS) == 1
+	}
+	return false
+}
+
+// Remembers that the server was detected as HttpFS.
+func (fs *FileSystem) detectedHttpFS() {
+	if fs.Config.Flavor == FlavorAuto && fs.httpFS != nil {
+		atomic.StoreInt32(fs.httpFS, 1)
+	}
+}
+
+// Returns u with data=true, the HttpFS upload form of a write request.
+func httpFSUploadUrl(u *url.URL) *url.URL {
+	up := *u
+	q := up.Query()
+	q.Set("data", "true")
+	up.RawQuery = q.Encode()
+	return &up
+}
+
+// Returns true when u is an HttpFS upload URL (i.e. the Location HttpFS
+// answers to a write sent without data=true).
+func isHttpFSUploadUrl(u *url.URL) bool {
+	return u.Query().Get("data") == "true"
+}
@@ -0,0 +1,100 @@
+package gowfs
+
+import "bytes"
+import "io/ioutil"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "sync/atomic"
+import "testing"
+
+func Test_HttpFS_Create(t *testing.T) {
+	server := mockServerFor_HttpFS(false, nil)
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, User: "hdfs", Flavor: FlavorHttpFS})
+
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fs.Append(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_HttpFS_Detect(t *testing.T) {
+	var redirects int32
+	server := mockServerFor_HttpFS(true, &redirects)
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, User: "hdfs"})
+
+	for i := 0; i < 2; i++ {
+		_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, true, 0, 0, 0700, 0, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if redirects != 1 {
+		t.Errorf("Expecting HttpFS to be detected after the first redirect, but got %d redirects", redirects)
+	}
+}
+
+func Test_HttpFS_NoRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, User: "hdfs"})
+
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err == nil {
+		t.Fatal("Expecting an error when the server does not redirect the write.")
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// HttpFS stand-in.  Uploads must have data=true and the octet-stream
+// content type.  With redirect, writes without data=true are redirected
+// to their upload URL, like HttpFS does.
+func mockServerFor_HttpFS(redirect bool, redirects *int32) *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		expectedMethod, expectedStatus := "PUT", http.StatusCreated
+		if q.Get("op") == OP_APPEND {
+			expectedMethod, expectedStatus = "POST", http.StatusOK
+		}
+		if req.Method != expectedMethod {
+			log.Fatalf("Expecting Request.Method %v, but got %v", expectedMethod, req.Method)
+		}
+		if q.Get("data") != "true" {
+			if !redirect {
+				log.Fatalf("Expecting param data=true, but got [url=%v]", req.URL)
+			}
+			atomic.AddInt32(redirects, 1)
+			q.Set("data", "true")
+			loc := *req.URL
+			loc.Scheme, loc.Host, loc.RawQuery = "http", req.Host, q.Encode()
+			rsp.Header().Set("Location", loc.String())
+			rsp.WriteHeader(http.StatusTemporaryRedirect)
+			return
+		}
+		if req.Header.Get("Content-Type") != HttpFSContentType {
+			log.Fatalf("Expecting Content-Type %v, but got %v", HttpFSContentType, req.Header.Get("Content-Type"))
+		}
+		data, _ := ioutil.ReadAll(req.Body)
+		if string(data) != "Hello webhdfs users!" {
+			log.Fatalf("Expected data not posted to server. Server got %v", string(data))
+		}
+		rsp.WriteHeader(expectedStatus)
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
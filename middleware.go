@@ -0,0 +1,25 @@
+package gowfs
+
+import "net/http"
+
+// Wraps a RoundTripper to observe or modify requests and responses
+// (i.e. add headers, log, trace).  See Configuration.Middleware.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// Adapter to use an ordinary function as an http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Wraps rt with the middleware.  The first middleware is the outermost,
+// it sees requests first and responses last.
+func chainMiddleware(rt http.RoundTripper, middleware []Middleware) http.RoundTripper {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			rt = middleware[i](rt)
+		}
+	}
+	return rt
+}
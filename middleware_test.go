@@ -0,0 +1,58 @@
+package gowfs
+
+import "bytes"
+import "io/ioutil"
+import "net/http"
+import "strings"
+import "testing"
+
+func Test_Middleware(t *testing.T) {
+	var calls []string
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("X-Request-Source") != "gateway" {
+			t.Errorf("Expecting header X-Request-Source set by middleware [url=%v]", req.URL)
+		}
+		calls = append(calls, req.URL.Host)
+		rsp := &http.Response{StatusCode: http.StatusCreated, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}
+		if req.URL.Host == "namenode:50070" {
+			rsp.StatusCode = http.StatusTemporaryRedirect
+			rsp.Header.Set("Location", "http://datanode:50075"+req.URL.RequestURI())
+		}
+		return rsp, nil
+	})
+	var order []string
+	logging := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	header := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("X-Request-Source", "gateway")
+			return next.RoundTrip(req)
+		})
+	}
+
+	conf := Configuration{
+		Addr:       "namenode:50070",
+		User:       "hdfs",
+		Transport:  transport,
+		Middleware: []Middleware{logging("outer"), logging("inner"), header},
+	}
+	fs, _ := NewFileSystem(conf)
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(calls, ",") != "namenode:50070,datanode:50075" {
+		t.Errorf("Expecting namenode and datanode requests through the transport, but got %v", calls)
+	}
+	if strings.Join(order, ",") != "outer,inner,outer,inner" {
+		t.Errorf("Expecting middleware applied in order to both requests, but got %v", order)
+	}
+}
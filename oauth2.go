@@ -0,0 +1,234 @@
+package gowfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth2 grant types supported by OAuth2Authenticator.
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// Authenticator for WebHDFS-compatible endpoints (i.e. cloud data lakes,
+// Knox behind an identity provider) expecting OAuth2 bearer tokens.
+// Access tokens are obtained from TokenURL with the client-credentials or
+// refresh-token grant, cached, and refreshed ExpiryDelta before they
+// expire.  A request rejected with 401 is sent once more with a new token.
+// The user is identified by the token, so user.name is not sent.
+type OAuth2Authenticator struct {
+	TokenURL     string        // token endpoint of the identity provider
+	GrantType    string        // GrantClientCredentials or GrantRefreshToken
+	ClientID     string        // client (application) id
+	ClientSecret string        // optional with the refresh-token grant
+	RefreshToken string        // required with the refresh-token grant, updated when rotated
+	Scopes       []string      // optional
+	Params       url.Values    // optional, extra token request parameters (i.e. resource)
+	ExpiryDelta  time.Duration // refresh tokens this long before they expire
+	Client       *http.Client  // used for token requests, defaults to a client with a 30s timeout
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time // zero when the token endpoint did not provide expires_in
+}
+
+// Creates an OAuth2Authenticator using the client-credentials grant.
+func NewOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) *OAuth2Authenticator {
+	return &OAuth2Authenticator{
+		TokenURL:     tokenURL,
+		GrantType:    GrantClientCredentials,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+		ExpiryDelta:  30 * time.Second,
+	}
+}
+
+// Creates an OAuth2Authenticator using the refresh-token grant.
+func NewOAuth2RefreshToken(tokenURL, clientID, refreshToken string) *OAuth2Authenticator {
+	return &OAuth2Authenticator{
+		TokenURL:     tokenURL,
+		GrantType:    GrantRefreshToken,
+		ClientID:     clientID,
+		RefreshToken: refreshToken,
+		ExpiryDelta:  30 * time.Second,
+	}
+}
+
+// Access token response, see RFC 6749 section 5.
+type oauth2TokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	RefreshToken     string      `json:"refresh_token"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// The token is sent to every host.  NewFileSystem() only sends it to the
+// namenodes, see wrapNameNodes().
+func (auth *OAuth2Authenticator) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return &oauth2Transport{auth: auth, next: rt}
+}
+
+// Bearer tokens are not sent to datanodes, which are given a delegation
+// token by the namenode when redirecting.
+func (auth *OAuth2Authenticator) wrapNameNodes(rt http.RoundTripper, hosts []string) http.RoundTripper {
+	return &oauth2Transport{auth: auth, hosts: hosts, next: rt}
+}
+
+func (auth *OAuth2Authenticator) IdentifiesUser() bool {
+	return true
+}
+
+// Returns the cached access token, requesting a new one when it is
+// missing or about to expire.
+func (auth *OAuth2Authenticator) AccessToken() (string, error) {
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	if auth.token != "" && (auth.expires.IsZero() || time.Now().Add(auth.ExpiryDelta).Before(auth.expires)) {
+		return auth.token, nil
+	}
+	return auth.fetch()
+}
+
+// Drops the cached access token if it is still token.
+func (auth *OAuth2Authenticator) invalidate(token string) {
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	if auth.token == token {
+		auth.token = ""
+	}
+}
+
+// Requests an access token from TokenURL.  Must be called with mu held.
+func (auth *OAuth2Authenticator) fetch() (string, error) {
+	form := url.Values{}
+	for key, vals := range auth.Params {
+		form[key] = vals
+	}
+	form.Set("grant_type", auth.GrantType)
+	switch auth.GrantType {
+	case GrantClientCredentials:
+	case GrantRefreshToken:
+		if auth.RefreshToken == "" {
+			return "", fmt.Errorf("OAuth2Authenticator - RefreshToken is required for the refresh_token grant.")
+		}
+		form.Set("refresh_token", auth.RefreshToken)
+	default:
+		return "", fmt.Errorf("OAuth2Authenticator - unsupported grant type %q.", auth.GrantType)
+	}
+	if auth.ClientID != "" {
+		form.Set("client_id", auth.ClientID)
+	}
+	if auth.ClientSecret != "" {
+		form.Set("client_secret", auth.ClientSecret)
+	}
+	if len(auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+
+	client := auth.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	rsp, err := client.PostForm(auth.TokenURL, form)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var tokenRsp oauth2TokenResponse
+	jsonErr := json.Unmarshal(body, &tokenRsp)
+	if tokenRsp.Error != "" {
+		return "", fmt.Errorf("OAuth2Authenticator - token request failed: %s %s", tokenRsp.Error, tokenRsp.ErrorDescription)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OAuth2Authenticator - token request failed.  Server returned status %v", rsp.StatusCode)
+	}
+	if jsonErr != nil {
+		return "", fmt.Errorf("OAuth2Authenticator - invalid token response: %s", jsonErr.Error())
+	}
+	if tokenRsp.AccessToken == "" {
+		return "", fmt.Errorf("OAuth2Authenticator - token response has no access_token.")
+	}
+	if tokenRsp.TokenType != "" && !strings.EqualFold(tokenRsp.TokenType, "bearer") {
+		return "", fmt.Errorf("OAuth2Authenticator - unsupported token type %s.", tokenRsp.TokenType)
+	}
+
+	auth.token = tokenRsp.AccessToken
+	auth.expires = time.Time{}
+	if secs, err := tokenRsp.ExpiresIn.Int64(); err == nil && secs > 0 {
+		auth.expires = time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	if tokenRsp.RefreshToken != "" {
+		auth.RefreshToken = tokenRsp.RefreshToken // rotated
+	}
+	return auth.token, nil
+}
+
+// Sets the Authorization header on requests to hosts, or to any host
+// when hosts is nil.  On a 401 response, the token is refreshed and the
+// request sent once more, when its body can be replayed.
+type oauth2Transport struct {
+	auth  *OAuth2Authenticator
+	hosts []string
+	next  http.RoundTripper
+}
+
+func (t *oauth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.authenticates(req.URL.Host) {
+		return t.next.RoundTrip(req)
+	}
+	token, err := t.auth.AccessToken()
+	if err != nil {
+		return nil, err
+	}
+	r := cloneRequest(req)
+	r.Header.Set("Authorization", "Bearer "+token)
+	rsp, err := t.next.RoundTrip(r)
+	if err != nil || rsp.StatusCode != http.StatusUnauthorized {
+		return rsp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return rsp, nil
+	}
+
+	t.auth.invalidate(token)
+	token, err = t.auth.AccessToken()
+	if err != nil {
+		return rsp, nil // report the original 401
+	}
+	rsp.Body.Close()
+	r = cloneRequest(req)
+	if req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	return t.next.RoundTrip(r)
+}
+
+func (t *oauth2Transport) authenticates(host string) bool {
+	if t.hosts == nil {
+		return true
+	}
+	for _, h := range t.hosts {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package gowfs
+
+import "bytes"
+import "fmt"
+import "log"
+import "net/http"
+import "net/http/httptest"
+import "net/url"
+import "sync/atomic"
+import "testing"
+
+func Test_OAuth2ClientCredentials(t *testing.T) {
+	var tokenRequests int32
+	tokenServer := mockServerFor_OAuth2Token(func(req *http.Request) string {
+		n := atomic.AddInt32(&tokenRequests, 1)
+		if req.PostForm.Get("grant_type") != GrantClientCredentials {
+			log.Fatalf("Expecting grant_type=client_credentials, but got %v", req.PostForm.Get("grant_type"))
+		}
+		if req.PostForm.Get("client_id") != "gowfs" || req.PostForm.Get("client_secret") != "secret" {
+			log.Fatalf("Expecting client credentials, but got %v", req.PostForm)
+		}
+		if req.PostForm.Get("scope") != "hdfs.read hdfs.write" {
+			log.Fatalf("Expecting scope param, but got %v", req.PostForm.Get("scope"))
+		}
+		return fmt.Sprintf(`{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, n)
+	})
+	defer tokenServer.Close()
+	server := mockServerFor_BearerListStatus("token-1")
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	auth := NewOAuth2ClientCredentials(tokenServer.URL, "gowfs", "secret", "hdfs.read", "hdfs.write")
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, Authenticator: auth})
+
+	for i := 0; i < 2; i++ {
+		if _, err := fs.ListStatus(Path{Name: "/test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expecting the access token to be cached, but got %d token requests", tokenRequests)
+	}
+}
+
+func Test_OAuth2RefreshToken(t *testing.T) {
+	var tokenRequests int32
+	tokenServer := mockServerFor_OAuth2Token(func(req *http.Request) string {
+		n := atomic.AddInt32(&tokenRequests, 1)
+		if req.PostForm.Get("grant_type") != GrantRefreshToken {
+			log.Fatalf("Expecting grant_type=refresh_token, but got %v", req.PostForm.Get("grant_type"))
+		}
+		if expected := fmt.Sprintf("refresh-%d", n); req.PostForm.Get("refresh_token") != expected {
+			log.Fatalf("Expecting refresh_token=%s, but got %v", expected, req.PostForm.Get("refresh_token"))
+		}
+		// expires within ExpiryDelta, so it is refreshed on every request.
+		return fmt.Sprintf(`{"access_token":"token-%d","token_type":"bearer","expires_in":"10","refresh_token":"refresh-%d"}`, n, n+1)
+	})
+	defer tokenServer.Close()
+
+	auth := NewOAuth2RefreshToken(tokenServer.URL, "gowfs", "refresh-1")
+	for i := 1; i <= 2; i++ {
+		token, err := auth.AccessToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != fmt.Sprintf("token-%d", i) {
+			t.Errorf("Expecting token-%d, but got %s", i, token)
+		}
+	}
+	if auth.RefreshToken != "refresh-3" {
+		t.Errorf("Expecting the rotated refresh token to be kept, but got %s", auth.RefreshToken)
+	}
+}
+
+func Test_OAuth2Unauthorized(t *testing.T) {
+	var tokenRequests int32
+	tokenServer := mockServerFor_OAuth2Token(func(req *http.Request) string {
+		n := atomic.AddInt32(&tokenRequests, 1)
+		return fmt.Sprintf(`{"access_token":"token-%d","expires_in":3600}`, n)
+	})
+	defer tokenServer.Close()
+	// token-1 has been revoked by the server.
+	server := mockServerFor_BearerListStatus("token-2")
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	auth := NewOAuth2ClientCredentials(tokenServer.URL, "gowfs", "secret")
+	fs, _ := NewFileSystem(Configuration{Addr: u.Host, Authenticator: auth})
+
+	if _, err := fs.ListStatus(Path{Name: "/test"}); err != nil {
+		t.Fatal(err)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("Expecting a new token after 401, but got %d token requests", tokenRequests)
+	}
+}
+
+func Test_OAuth2_DirectDatanode(t *testing.T) {
+	tokenServer := mockServerFor_OAuth2Token(func(req *http.Request) string {
+		return `{"access_token":"token-1","expires_in":3600}`
+	})
+	defer tokenServer.Close()
+	datanode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "" {
+			log.Fatalf("Bearer tokens must not be sent to datanodes [url=%v]", req.URL)
+		}
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer datanode.Close()
+	namenode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token-1" {
+			log.Fatalf("Expecting the bearer token [url=%v]", req.URL)
+		}
+		rsp.Header().Set("Location", datanode.URL+req.URL.RequestURI())
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer namenode.Close()
+
+	auth := NewOAuth2ClientCredentials(tokenServer.URL, "gowfs", "secret")
+	fs, _ := NewFileSystem(Configuration{Endpoint: namenode.URL, Authenticator: auth})
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_OAuth2TokenError(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(rsp, `{"error":"invalid_client","error_description":"bad secret"}`)
+	}))
+	defer tokenServer.Close()
+
+	auth := NewOAuth2ClientCredentials(tokenServer.URL, "gowfs", "wrong")
+	if _, err := auth.AccessToken(); err == nil {
+		t.Fatal("Expecting an error from the token endpoint.")
+	}
+}
+
+func Test_GetNameNodeUrl_OAuth2(t *testing.T) {
+	conf := Configuration{Addr: "localhost:8080", User: "vvivien", Authenticator: NewOAuth2ClientCredentials("http://idp/token", "id", "secret")}
+	u, err := conf.GetNameNodeUrl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("user.name") != "" {
+		t.Errorf("Expecting no user.name param with OAuth2, but got [url=%v]", u)
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+// Token endpoint stand-in, answers with the JSON returned by tokenRsp.
+func mockServerFor_OAuth2Token(tokenRsp func(req *http.Request) string) *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			log.Fatalf("Expecting Request.Method POST, but got %v", req.Method)
+		}
+		if err := req.ParseForm(); err != nil {
+			log.Fatalf("Unable to parse token request: %v", err)
+		}
+		rsp.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rsp, tokenRsp(req))
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+// Answers LISTSTATUS for requests with the bearer token, 401 otherwise.
+func mockServerFor_BearerListStatus(token string) *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("user.name") != "" {
+			log.Fatalf("Expecting no user.name param, but got [url=%v]", req.URL)
+		}
+		if req.Header.Get("Authorization") != "Bearer "+token {
+			rsp.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(rsp, listStatusRsp)
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
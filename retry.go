@@ -0,0 +1,277 @@
+package gowfs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Largest error body inspected for a RemoteException by the transport.
+const maxPeekBody = 64 * 1024
+
+// Describes a failed attempt of an operation, see RetryClassifier.
+type RetryAttempt struct {
+	Op         string // WebHDFS operation, i.e. OP_LISTSTATUS
+	Idempotent bool   // the operation can safely be repeated
+	StatusCode int    // status of the failed response, 0 when none was received
+	Err        error  // network error or RemoteException, if any
+}
+
+// Decides whether a failed attempt should be retried.
+type RetryClassifier func(attempt RetryAttempt) bool
+
+// Retry policy for transient failures.  Attempt n (n > 1) waits
+// BaseDelay * 2^(n-2), capped at MaxDelay, reduced by up to Jitter
+// (a fraction between 0 and 1) of the delay.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts, including the first one
+	BaseDelay   time.Duration // delay before the first retry
+	MaxDelay    time.Duration
+	Jitter      float64
+	Classifier  RetryClassifier // defaults to DefaultRetryClassifier
+}
+
+// Returns a RetryPolicy with default values.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.5,
+		Classifier:  DefaultRetryClassifier,
+	}
+}
+
+// Remote exceptions raised before the operation was processed.  Retrying
+// them is safe for any operation.
+var retriableExceptions = map[string]bool{
+	"RetriableException": true,
+	"SafeModeException":  true,
+	"StandbyException":   true,
+}
+
+// Retries failures that happened before the operation was processed
+// (connection failures, RetriableException, SafeModeException, ...).
+// Idempotent operations are also retried on other network errors and
+// on 5xx responses.
+func DefaultRetryClassifier(attempt RetryAttempt) bool {
+	if re, ok := attempt.Err.(RemoteException); ok && retriableExceptions[re.Exception] {
+		return true
+	}
+	if isDialError(attempt.Err) {
+		return true
+	}
+	if !attempt.Idempotent {
+		return false
+	}
+	if _, ok := attempt.Err.(net.Error); ok {
+		return true
+	}
+	return attempt.StatusCode >= 500
+}
+
+// Returns true when the WebHDFS operation can be repeated without changing
+// its outcome.  CREATE is idempotent only when it overwrites.
+func IsIdempotent(op string, overwrite bool) bool {
+	switch op {
+	case OP_OPEN, OP_GETFILESTATUS, OP_LISTSTATUS, OP_GETCONTENTSUMMARY, OP_GETFILECHECKSUM,
+		OP_GETDELEGATIONTOKENS, OP_SETPERMISSION, OP_SETOWNER, OP_SETREPLICATION, OP_SETTIMES,
+		OP_MKDIRS, OP_RENEWDELEGATIONTOKEN, OP_GETHOMEDIRECTORY, OP_TRUNCATE,
+		OP_GETXATTRS, OP_LISTXATTRS, OP_GETACLSTATUS, OP_SETACL, OP_MODIFYACLENTRIES,
+		OP_REMOVEACLENTRIES, OP_REMOVEDEFAULTACL, OP_REMOVEACL, OP_ALLOWSNAPSHOT, OP_DISALLOWSNAPSHOT,
+		OP_GETSNAPSHOTTABLEDIRECTORYLIST, OP_GETSNAPSHOTLIST, OP_GETSNAPSHOTDIFF, OP_GETSNAPSHOTDIFFLISTING,
+		OP_GETALLSTORAGEPOLICY, OP_GETSTORAGEPOLICY, OP_SETSTORAGEPOLICY, OP_UNSETSTORAGEPOLICY, OP_SATISFYSTORAGEPOLICY,
+		OP_ENABLEECPOLICY, OP_DISABLEECPOLICY, OP_SETECPOLICY, OP_GETECPOLICY, OP_UNSETECPOLICY,
+		OP_GETQUOTAUSAGE, OP_SETQUOTA, OP_SETQUOTABYSTORAGETYPE:
+		return true
+	case OP_CREATE:
+		return overwrite
+	}
+	return false
+}
+
+func (policy *RetryPolicy) shouldRetry(attempt RetryAttempt, n int) bool {
+	if n >= policy.MaxAttempts {
+		return false
+	}
+	classify := policy.Classifier
+	if classify == nil {
+		classify = DefaultRetryClassifier
+	}
+	return classify(attempt)
+}
+
+// Returns the delay before attempt n+1.
+func (policy *RetryPolicy) delay(n int) time.Duration {
+	d := policy.BaseDelay
+	for i := 1; i < n && (policy.MaxDelay <= 0 || d < policy.MaxDelay); i++ {
+		d *= 2
+	}
+	if policy.MaxDelay > 0 && d > policy.MaxDelay {
+		d = policy.MaxDelay
+	}
+	if policy.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * policy.Jitter * float64(d))
+	}
+	return d
+}
+
+// Waits for the delay before attempt n+1, or until ctx ends.
+func (policy *RetryPolicy) wait(ctx context.Context, n int) error {
+	timer := time.NewTimer(policy.delay(n))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Retries requests according to the policy.  CREATE and APPEND requests
+// are passed through: they are retried by FileSystem.retryWrite from the
+// namenode step.
+type retryTransport struct {
+	policy *RetryPolicy
+	next   http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	op := q.Get("op")
+	if op == OP_CREATE || op == OP_APPEND || (req.Body != nil && req.GetBody == nil) {
+		return t.next.RoundTrip(req)
+	}
+	overwrite, _ := strconv.ParseBool(q.Get("overwrite"))
+	idempotent := IsIdempotent(op, overwrite)
+
+	for n := 1; ; n++ {
+		r := req
+		if n > 1 && req.GetBody != nil {
+			r = cloneRequest(req)
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+		rsp, err := t.next.RoundTrip(r)
+		attempt := RetryAttempt{Op: op, Idempotent: idempotent, Err: err}
+		if err == nil {
+			if rsp.StatusCode < 400 {
+				return rsp, nil
+			}
+			attempt.StatusCode = rsp.StatusCode
+			attempt.Err = peekRemoteException(rsp)
+		}
+		if !t.policy.shouldRetry(attempt, n) {
+			return rsp, err
+		}
+		if rsp != nil {
+			rsp.Body.Close()
+		}
+		if err := t.policy.wait(req.Context(), n); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Runs write, a two-step CREATE or APPEND, retrying it from the namenode
+// step according to Config.RetryPolicy.  data is rewound between attempts
+// when it implements io.Seeker, otherwise attempts that already consumed
+// data are not retried.
+func (fs *FileSystem) retryWrite(
+	ctx context.Context,
+	op string,
+	idempotent bool,
+	data io.Reader,
+	write func(data io.Reader) (int, error)) error {
+
+	policy := fs.Config.RetryPolicy
+	if policy == nil {
+		_, err := write(data)
+		return err
+	}
+
+	// buffers are read through a bytes.Reader so they can be rewound.
+	if buf, ok := data.(*bytes.Buffer); ok {
+		data = bytes.NewReader(buf.Bytes())
+	}
+	seeker, seekable := data.(io.Seeker)
+	var start int64
+	if seekable {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seekable = false
+		}
+	}
+
+	for n := 1; ; n++ {
+		var counter *countingReader
+		body := data
+		if data != nil && !seekable {
+			counter = &countingReader{r: data}
+			body = counter
+		}
+		status, err := write(body)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*IncompleteWriteError); ok {
+			return err
+		}
+		attempt := RetryAttempt{Op: op, Idempotent: idempotent, StatusCode: status, Err: err}
+		if !policy.shouldRetry(attempt, n) {
+			return err
+		}
+		if counter != nil && counter.n > 0 {
+			return err // data can't be sent again.
+		}
+		if seekable {
+			if _, serr := seeker.Seek(start, io.SeekStart); serr != nil {
+				return err
+			}
+		}
+		if err := policy.wait(ctx, n); err != nil {
+			return err
+		}
+	}
+}
+
+// Counts bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Returns the RemoteException carried by an error response, if any.
+// The response body is restored so it can still be read by the caller.
+func peekRemoteException(rsp *http.Response) error {
+	body := rsp.Body
+	head, err := ioutil.ReadAll(io.LimitReader(body, maxPeekBody))
+	rsp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), body), body}
+	if err != nil {
+		return nil
+	}
+	if _, err := makeHdfsData(head); err != nil {
+		if re, ok := err.(RemoteException); ok {
+			return re
+		}
+	}
+	return nil
+}
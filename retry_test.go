@@ -0,0 +1,140 @@
+package gowfs
+
+import "bytes"
+import "context"
+import "errors"
+import "fmt"
+import "io/ioutil"
+import "log"
+import "net/url"
+import "net/http"
+import "net/http/httptest"
+import "sync/atomic"
+import "time"
+
+import "testing"
+
+const safeModeExceptionRsp = `
+{
+  "RemoteException":
+  {
+    "exception"    : "SafeModeException",
+    "javaClassName": "org.apache.hadoop.hdfs.server.namenode.SafeModeException",
+    "message"      : "Cannot create file. Name node is in safe mode."
+  }
+}
+`
+
+func testRetryPolicy() *RetryPolicy {
+	policy := NewRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxAttempts = 3
+	return policy
+}
+
+func Test_RetryPolicy_Idempotent(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			rsp.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(rsp, listStatusRsp)
+	}))
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, RetryPolicy: testRetryPolicy()})
+
+	stats, err := fs.ListStatus(Path{Name: "/test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || hits != 3 {
+		t.Errorf("Expecting 3 attempts, but got %d", hits)
+	}
+}
+
+func Test_RetryPolicy_NotIdempotent(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		rsp.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, RetryPolicy: testRetryPolicy()})
+
+	fs.Rename(Path{Name: "/testing"}, Path{Name: "/testing/newname"})
+	if hits != 1 {
+		t.Errorf("Expecting RENAME not to be retried, but got %d attempts", hits)
+	}
+}
+
+func Test_RetryPolicy_CreateInSafeMode(t *testing.T) {
+	var nnHits, dnHits int32
+	datanode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&dnHits, 1)
+		data, _ := ioutil.ReadAll(req.Body)
+		if string(data) != "Hello webhdfs users!" {
+			log.Fatalf("Expected data not posted to server. Server got %v", string(data))
+		}
+		rsp.WriteHeader(http.StatusCreated)
+	}))
+	defer datanode.Close()
+	namenode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&nnHits, 1) == 1 {
+			rsp.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(rsp, safeModeExceptionRsp)
+			return
+		}
+		rsp.Header().Set("Location", datanode.URL+req.URL.String())
+		rsp.WriteHeader(http.StatusTemporaryRedirect)
+	}))
+	defer namenode.Close()
+
+	url, _ := url.Parse(namenode.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, RetryPolicy: testRetryPolicy()})
+
+	_, err := fs.Create(bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nnHits != 2 || dnHits != 1 {
+		t.Errorf("Expecting 2 namenode and 1 datanode requests, but got %d and %d", nnHits, dnHits)
+	}
+}
+
+func Test_RetryPolicy_CreateDeadline(t *testing.T) {
+	namenode := httptest.NewServer(http.HandlerFunc(func(rsp http.ResponseWriter, req *http.Request) {
+		rsp.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(rsp, safeModeExceptionRsp)
+	}))
+	defer namenode.Close()
+
+	url, _ := url.Parse(namenode.URL)
+	policy := testRetryPolicy()
+	policy.BaseDelay = time.Hour
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host, RetryPolicy: policy})
+
+	// the deadline expires while waiting to retry.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := fs.CreateContext(ctx, bytes.NewBufferString("Hello webhdfs users!"), Path{Name: "/testing/newfile"}, false, 0, 0, 0700, 0, "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expecting context.DeadlineExceeded, but got %v", err)
+	}
+}
+
+func Test_IsIdempotent(t *testing.T) {
+	if !IsIdempotent(OP_GETFILESTATUS, false) || !IsIdempotent(OP_LISTSTATUS, false) {
+		t.Error("Expecting GETFILESTATUS and LISTSTATUS to be idempotent.")
+	}
+	if IsIdempotent(OP_CREATE, false) || !IsIdempotent(OP_CREATE, true) {
+		t.Error("Expecting only overwriting CREATE to be idempotent.")
+	}
+	if IsIdempotent(OP_APPEND, false) || IsIdempotent(OP_RENAME, false) {
+		t.Error("Expecting APPEND and RENAME not to be idempotent.")
+	}
+}
@@ -0,0 +1,243 @@
+package gowfs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Name of the cookie issued by Hadoop's AuthenticationFilter once a
+// client has been authenticated.
+const HadoopAuthCookie = "hadoop.auth"
+
+// Authenticator attaches credentials to every request sent by a FileSystem,
+// including the redirected requests sent to datanodes.
+type Authenticator interface {
+	// Wrap returns a RoundTripper that authenticates requests before
+	// handing them to rt.
+	Wrap(rt http.RoundTripper) http.RoundTripper
+}
+
+// Implemented by authenticators whose credentials must only be sent to
+// the namenodes (hosts).  NewFileSystem() uses it instead of Wrap().
+type nameNodeAuthenticator interface {
+	wrapNameNodes(rt http.RoundTripper, hosts []string) http.RoundTripper
+}
+
+// Implemented by authenticators whose credentials identify the user
+// (i.e. OAuth2 bearer tokens).  The user.name parameter is not sent
+// when IdentifiesUser returns true.
+type UserIdentifier interface {
+	IdentifiesUser() bool
+}
+
+// KerberosClient produces SPNEGO (RFC 4559) tokens for a service principal.
+// gowfs does not ship a Kerberos implementation; adapt a Kerberos library
+// (or GSSAPI binding) to this interface.
+type KerberosClient interface {
+	// SpnegoToken returns the initial context token for service
+	// principal spn (i.e. HTTP/namenode.example.com).
+	SpnegoToken(spn string) ([]byte, error)
+}
+
+// Kerberos login settings handed to a KerberosLoginFunc.  gowfs does not
+// read keytabs or credential caches: the settings are only checked, the
+// login is done by the function.  Either Keytab (with Principal) or CCache
+// must be provided.  When both are empty, the credential cache named by
+// the KRB5CCNAME environment variable is passed.
+type KerberosLoginParams struct {
+	Principal string // client principal (user@REALM)
+	Realm     string // realm, if not part of Principal
+	Keytab    string // keytab file used to log in Principal
+	CCache    string // credential cache file (kinit)
+	Krb5Conf  string // krb5.conf location, defaults to /etc/krb5.conf
+}
+
+// Function that performs the Kerberos login described by
+// KerberosLoginParams (i.e. with a Kerberos library) and returns a client
+// able to produce SPNEGO tokens.
+type KerberosLoginFunc func(login KerberosLoginParams) (KerberosClient, error)
+
+// Validates the login settings, filling in defaults from the environment.
+func (login *KerberosLoginParams) validate() error {
+	if login.Keytab != "" && login.CCache != "" {
+		return fmt.Errorf("KerberosLoginParams - only one of Keytab or CCache can be set.")
+	}
+	if login.Keytab != "" {
+		if login.Principal == "" {
+			return fmt.Errorf("KerberosLoginParams - Principal is required for keytab login.")
+		}
+		if _, err := os.Stat(login.Keytab); err != nil {
+			return fmt.Errorf("KerberosLoginParams - keytab %s: %s", login.Keytab, err.Error())
+		}
+	}
+	if login.Keytab == "" && login.CCache == "" {
+		login.CCache = strings.TrimPrefix(os.Getenv("KRB5CCNAME"), "FILE:")
+		if login.CCache == "" {
+			return fmt.Errorf("KerberosLoginParams - Keytab or CCache must be provided.")
+		}
+	}
+	if login.Krb5Conf == "" {
+		login.Krb5Conf = "/etc/krb5.conf"
+	}
+	return nil
+}
+
+// Authenticator for Kerberized clusters using SPNEGO (HTTP Negotiate).
+// Requests are first sent with the cached hadoop.auth cookie for the host,
+// if any.  When the server answers with a 401 Negotiate challenge, a
+// SPNEGO token is obtained from Client and the request is sent again.
+// The hadoop.auth cookie returned by the server is cached so later
+// requests do not renegotiate.
+type SpnegoAuthenticator struct {
+	Client           KerberosClient
+	ServicePrincipal string // defaults to HTTP/<request host>
+
+	mu      sync.Mutex
+	cookies map[string]*http.Cookie
+}
+
+// Checks login, calls loginFn with it and returns an authenticator using
+// the resulting client.
+func NewSpnegoAuthenticatorWithLogin(login KerberosLoginParams, loginFn KerberosLoginFunc) (*SpnegoAuthenticator, error) {
+	if loginFn == nil {
+		return nil, fmt.Errorf("NewSpnegoAuthenticatorWithLogin() - a KerberosLoginFunc is required.")
+	}
+	if err := login.validate(); err != nil {
+		return nil, err
+	}
+	client, err := loginFn(login)
+	if err != nil {
+		return nil, err
+	}
+	return &SpnegoAuthenticator{Client: client}, nil
+}
+
+func (auth *SpnegoAuthenticator) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return &spnegoTransport{auth: auth, next: rt}
+}
+
+// The user is the Kerberos principal: a user.name differing from it is
+// rejected by secure clusters.
+func (auth *SpnegoAuthenticator) IdentifiesUser() bool {
+	return true
+}
+
+// Returns the cached hadoop.auth cookie for host.
+func (auth *SpnegoAuthenticator) cookie(host string) *http.Cookie {
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	return auth.cookies[host]
+}
+
+func (auth *SpnegoAuthenticator) setCookie(host string, c *http.Cookie) {
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	if auth.cookies == nil {
+		auth.cookies = make(map[string]*http.Cookie)
+	}
+	if c == nil {
+		delete(auth.cookies, host)
+	} else {
+		auth.cookies[host] = c
+	}
+}
+
+// Returns the Negotiate authorization header value for host.
+func (auth *SpnegoAuthenticator) negotiate(host string) (string, error) {
+	if auth.Client == nil {
+		return "", fmt.Errorf("SpnegoAuthenticator - KerberosClient not set.")
+	}
+	spn := auth.ServicePrincipal
+	if spn == "" {
+		if i := strings.LastIndex(host, ":"); i > 0 {
+			host = host[:i]
+		}
+		spn = "HTTP/" + host
+	}
+	token, err := auth.Client.SpnegoToken(spn)
+	if err != nil {
+		return "", err
+	}
+	return "Negotiate " + base64.StdEncoding.EncodeToString(token), nil
+}
+
+type spnegoTransport struct {
+	auth *SpnegoAuthenticator
+	next http.RoundTripper
+}
+
+func (t *spnegoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	r := cloneRequest(req)
+	if c := t.auth.cookie(host); c != nil {
+		r.AddCookie(c)
+	}
+	rsp, err := t.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusUnauthorized || !isNegotiateChallenge(rsp) {
+		t.keepCookie(host, rsp)
+		return rsp, nil
+	}
+
+	// server wants to negotiate, stale cookie (if any) is dropped.
+	t.auth.setCookie(host, nil)
+	if req.Body != nil && req.GetBody == nil {
+		return rsp, nil // request body cannot be replayed.
+	}
+	authz, err := t.auth.negotiate(host)
+	if err != nil {
+		rsp.Body.Close()
+		return nil, err
+	}
+	rsp.Body.Close()
+
+	r = cloneRequest(req)
+	if req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	r.Header.Set("Authorization", authz)
+	rsp, err = t.next.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	t.keepCookie(host, rsp)
+	return rsp, nil
+}
+
+// Caches the hadoop.auth cookie set by the server, if any.
+func (t *spnegoTransport) keepCookie(host string, rsp *http.Response) {
+	for _, c := range rsp.Cookies() {
+		if c.Name == HadoopAuthCookie && c.Value != "" {
+			t.auth.setCookie(host, &http.Cookie{Name: c.Name, Value: c.Value})
+		}
+	}
+}
+
+func isNegotiateChallenge(rsp *http.Response) bool {
+	for _, v := range rsp.Header["Www-Authenticate"] {
+		if strings.HasPrefix(strings.ToLower(v), "negotiate") {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a shallow copy of req with its own header map, so a
+// RoundTripper can add headers without modifying the caller's request.
+func cloneRequest(req *http.Request) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	return r
+}
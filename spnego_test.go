@@ -0,0 +1,128 @@
+package gowfs
+
+import "fmt"
+import "io/ioutil"
+import "log"
+import "net/url"
+import "net/http"
+import "net/http/httptest"
+import "os"
+
+import "testing"
+
+type mockKerberosClient struct {
+	calls int
+	spn   string
+}
+
+func (c *mockKerberosClient) SpnegoToken(spn string) ([]byte, error) {
+	c.calls++
+	c.spn = spn
+	return []byte("negotiate-token"), nil
+}
+
+func Test_SpnegoAuthenticator(t *testing.T) {
+	server := mockServerFor_Spnego()
+	defer server.Close()
+	t.Logf("Test_SpnegoAuthenticator - Started httptest.Server on %v", server.URL)
+
+	krb := &mockKerberosClient{}
+	url, _ := url.Parse(server.URL)
+	conf := Configuration{Addr: url.Host, Authenticator: &SpnegoAuthenticator{Client: krb}}
+	fs, _ := NewFileSystem(conf)
+
+	stats, err := fs.ListStatus(Path{Name: "/test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatal("ListStatus() - not returning data through SPNEGO.")
+	}
+
+	// second call must reuse the hadoop.auth cookie
+	if _, err := fs.ListStatus(Path{Name: "/test"}); err != nil {
+		t.Fatal(err)
+	}
+	if krb.calls != 1 {
+		t.Errorf("Expecting 1 negotiation, but got %d", krb.calls)
+	}
+	if krb.spn != "HTTP/127.0.0.1" {
+		t.Errorf("Expecting service principal HTTP/127.0.0.1, but got %s", krb.spn)
+	}
+}
+
+func Test_SpnegoAuthenticator_NoUserName(t *testing.T) {
+	server := mockServerFor_Spnego()
+	defer server.Close()
+
+	url, _ := url.Parse(server.URL)
+	conf := Configuration{Addr: url.Host, User: "alice", Authenticator: &SpnegoAuthenticator{Client: &mockKerberosClient{}}}
+	fs, _ := NewFileSystem(conf)
+
+	u, err := fs.Config.GetNameNodeUrl()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("user.name") != "" {
+		t.Errorf("Expecting no user.name with SPNEGO, but got %v", u)
+	}
+	if _, err := fs.ListStatus(Path{Name: "/test"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_NewSpnegoAuthenticatorWithLogin(t *testing.T) {
+	loginFn := func(login KerberosLoginParams) (KerberosClient, error) {
+		return &mockKerberosClient{}, nil
+	}
+
+	_, err := NewSpnegoAuthenticatorWithLogin(KerberosLoginParams{Keytab: "/some.keytab", CCache: "/tmp/krb5cc"}, loginFn)
+	if err == nil {
+		t.Error("Expecting error when both Keytab and CCache are set.")
+	}
+
+	keytab, _ := ioutil.TempFile("", "gowfs-keytab")
+	keytab.Close()
+	defer os.Remove(keytab.Name())
+
+	_, err = NewSpnegoAuthenticatorWithLogin(KerberosLoginParams{Keytab: keytab.Name()}, loginFn)
+	if err == nil {
+		t.Error("Expecting error when Principal is missing for keytab login.")
+	}
+
+	auth, err := NewSpnegoAuthenticatorWithLogin(KerberosLoginParams{Principal: "hdfs@EXAMPLE.COM", Keytab: keytab.Name()}, loginFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Client == nil {
+		t.Fatal("SpnegoAuthenticator - KerberosClient not set after login.")
+	}
+}
+
+// *********************** Mock Servers ********************* //
+
+func mockServerFor_Spnego() *httptest.Server {
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		// the user is the principal, as on a secure cluster.
+		if name := req.URL.Query().Get("user.name"); name != "" {
+			rsp.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(rsp, `{"RemoteException": {"exception": "SecurityException", "javaClassName": "java.lang.SecurityException", "message": "Failed to obtain user group information: java.io.IOException: Usernames not matched: name=%s != expected=hdfs"}}`, name)
+			return
+		}
+		if c, err := req.Cookie(HadoopAuthCookie); err == nil && c.Value == "u=hdfs&t=kerberos" {
+			fmt.Fprint(rsp, listStatusRsp)
+			return
+		}
+		if req.Header.Get("Authorization") != "Negotiate bmVnb3RpYXRlLXRva2Vu" {
+			if req.Header.Get("Authorization") != "" {
+				log.Fatalf("Unexpected Authorization header %v", req.Header.Get("Authorization"))
+			}
+			rsp.Header().Set("WWW-Authenticate", "Negotiate")
+			rsp.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(rsp, &http.Cookie{Name: HadoopAuthCookie, Value: "u=hdfs&t=kerberos"})
+		fmt.Fprint(rsp, listStatusRsp)
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
@@ -52,7 +52,7 @@ func testConnection(fs *gowfs.FileSystem) {
 	if err != nil {
 		log.Fatal("Unable to connect to server. ", err)
 	}
-	log.Printf("Connected to server %s... OK.\n", fs.Config.Addr)
+	log.Printf("Connected to server %s... OK.\n", fs.ActiveNameNode())
 }
 
 func ls(fs *gowfs.FileSystem, hdfsPath string) {
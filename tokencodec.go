@@ -0,0 +1,221 @@
+package gowfs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Version of the delegation token identifier Writable understood by Decode.
+const tokenIdentifierVersion byte = 0
+
+// Decoded content of a delegation token, as serialized by Hadoop's
+// Token and AbstractDelegationTokenIdentifier Writables.
+type DecodedToken struct {
+	Owner          string
+	Renewer        string
+	RealUser       string
+	IssueDate      int64 // milliseconds since epoch
+	MaxDate        int64 // milliseconds since epoch
+	SequenceNumber int32
+	MasterKeyId    int32
+	Kind           string // i.e. HDFS_DELEGATION_TOKEN
+	Service        string // i.e. 127.0.0.1:8020 or ha-hdfs:nameservice
+	Password       []byte
+}
+
+// Returns the time the token was issued.
+func (d DecodedToken) IssueTime() time.Time {
+	return msToTime(d.IssueDate)
+}
+
+// Returns the time after which the token can no longer be renewed.
+func (d DecodedToken) MaxTime() time.Time {
+	return msToTime(d.MaxDate)
+}
+
+// Decodes the URL-safe base64 urlString of the token into its fields.
+// No call to the namenode is made.
+func (t Token) Decode() (DecodedToken, error) {
+	data, err := decodeTokenString(t.UrlString)
+	if err != nil {
+		return DecodedToken{}, fmt.Errorf("Token.Decode() - invalid encoding: %s", err.Error())
+	}
+
+	var d DecodedToken
+	r := bytes.NewReader(data)
+	ident, err := readBytes(r)
+	if err == nil {
+		d.Password, err = readBytes(r)
+	}
+	if err == nil {
+		d.Kind, err = readText(r)
+	}
+	if err == nil {
+		d.Service, err = readText(r)
+	}
+	if err == nil && r.Len() != 0 {
+		err = fmt.Errorf("%d unexpected trailing bytes", r.Len())
+	}
+	if err == nil {
+		err = d.decodeIdentifier(ident)
+	}
+	if err != nil {
+		return DecodedToken{}, fmt.Errorf("Token.Decode() - malformed token: %s", err.Error())
+	}
+	return d, nil
+}
+
+// Encodes the fields into a Token.  This is the reverse of Token.Decode(),
+// mostly useful to build tokens for tests.
+func (d DecodedToken) Encode() Token {
+	var ident bytes.Buffer
+	ident.WriteByte(tokenIdentifierVersion)
+	writeText(&ident, d.Owner)
+	writeText(&ident, d.Renewer)
+	writeText(&ident, d.RealUser)
+	writeVLong(&ident, d.IssueDate)
+	writeVLong(&ident, d.MaxDate)
+	writeVLong(&ident, int64(d.SequenceNumber))
+	writeVLong(&ident, int64(d.MasterKeyId))
+
+	var buf bytes.Buffer
+	writeBytes(&buf, ident.Bytes())
+	writeBytes(&buf, d.Password)
+	writeText(&buf, d.Kind)
+	writeText(&buf, d.Service)
+	return Token{UrlString: base64.RawURLEncoding.EncodeToString(buf.Bytes())}
+}
+
+func (d *DecodedToken) decodeIdentifier(ident []byte) error {
+	r := bytes.NewReader(ident)
+	ver, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if ver != tokenIdentifierVersion {
+		return fmt.Errorf("unknown identifier version %d", ver)
+	}
+	if d.Owner, err = readText(r); err != nil {
+		return err
+	}
+	if d.Renewer, err = readText(r); err != nil {
+		return err
+	}
+	if d.RealUser, err = readText(r); err != nil {
+		return err
+	}
+	if d.IssueDate, err = readVLong(r); err != nil {
+		return err
+	}
+	if d.MaxDate, err = readVLong(r); err != nil {
+		return err
+	}
+	seq, err := readVLong(r)
+	if err != nil {
+		return err
+	}
+	key, err := readVLong(r)
+	if err != nil {
+		return err
+	}
+	d.SequenceNumber, d.MasterKeyId = int32(seq), int32(key)
+	if r.Len() != 0 {
+		return fmt.Errorf("%d unexpected trailing identifier bytes", r.Len())
+	}
+	return nil
+}
+
+// Hadoop encodes tokens with URL-safe base64 without padding, but
+// tokens pasted from other tools may be padded or use the standard alphabet.
+func decodeTokenString(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// ******************** Hadoop Writable encoding ******************** //
+// See org.apache.hadoop.io.WritableUtils and org.apache.hadoop.io.Text
+
+func readVLong(r io.ByteReader) (int64, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	b := int8(first)
+	if b >= -112 {
+		return int64(b), nil
+	}
+	size := -111 - int(b)
+	if b < -120 {
+		size = -119 - int(b)
+	}
+	var i int64
+	for idx := 0; idx < size-1; idx++ {
+		next, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		i = i<<8 | int64(next)
+	}
+	if b < -120 {
+		return ^i, nil
+	}
+	return i, nil
+}
+
+func writeVLong(w *bytes.Buffer, i int64) {
+	if i >= -112 && i <= 127 {
+		w.WriteByte(byte(int8(i)))
+		return
+	}
+	size := -112
+	if i < 0 {
+		i = ^i
+		size = -120
+	}
+	for tmp := i; tmp != 0; tmp >>= 8 {
+		size--
+	}
+	w.WriteByte(byte(int8(size)))
+	if size < -120 {
+		size = -(size + 120)
+	} else {
+		size = -(size + 112)
+	}
+	for idx := size; idx != 0; idx-- {
+		w.WriteByte(byte(i >> uint((idx-1)*8)))
+	}
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readVLong(r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > int64(r.Len()) {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func writeBytes(w *bytes.Buffer, data []byte) {
+	writeVLong(w, int64(len(data)))
+	w.Write(data)
+}
+
+func readText(r *bytes.Reader) (string, error) {
+	data, err := readBytes(r)
+	return string(data), err
+}
+
+func writeText(w *bytes.Buffer, s string) {
+	writeBytes(w, []byte(s))
+}
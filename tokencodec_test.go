@@ -0,0 +1,75 @@
+package gowfs
+
+import "bytes"
+import "testing"
+
+// Token built following Hadoop's Token/DelegationTokenIdentifier Writables.
+const encodedTokenFixture = "IQAEaGRmcwR5YXJuBW9vemlligFF9oCwAIoBRhqNNAAqBwRwYXNzFUhERlNfREVMRUdBVElPTl9UT0tFTgtoYS1oZGZzOm5zMQ"
+
+func Test_TokenDecode(t *testing.T) {
+	d, err := Token{UrlString: encodedTokenFixture}.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DecodedToken{
+		Owner:          "hdfs",
+		Renewer:        "yarn",
+		RealUser:       "oozie",
+		IssueDate:      1400000000000,
+		MaxDate:        1400604800000,
+		SequenceNumber: 42,
+		MasterKeyId:    7,
+		Kind:           "HDFS_DELEGATION_TOKEN",
+		Service:        "ha-hdfs:ns1",
+		Password:       []byte("pass"),
+	}
+	if d.Owner != expected.Owner || d.Renewer != expected.Renewer || d.RealUser != expected.RealUser {
+		t.Errorf("Expecting owner/renewer/realUser %v, but got %v", expected, d)
+	}
+	if d.IssueDate != expected.IssueDate || d.MaxDate != expected.MaxDate {
+		t.Errorf("Expecting dates %d/%d, but got %d/%d", expected.IssueDate, expected.MaxDate, d.IssueDate, d.MaxDate)
+	}
+	if d.SequenceNumber != expected.SequenceNumber || d.MasterKeyId != expected.MasterKeyId {
+		t.Errorf("Expecting sequence/key %d/%d, but got %d/%d", expected.SequenceNumber, expected.MasterKeyId, d.SequenceNumber, d.MasterKeyId)
+	}
+	if d.Kind != expected.Kind || d.Service != expected.Service || !bytes.Equal(d.Password, expected.Password) {
+		t.Errorf("Expecting kind/service %v, but got %v", expected, d)
+	}
+
+	if tok := expected.Encode(); tok.UrlString != encodedTokenFixture {
+		t.Errorf("Expecting encoded token %s, but got %s", encodedTokenFixture, tok.UrlString)
+	}
+}
+
+func Test_TokenEncodeRoundTrip(t *testing.T) {
+	values := []int64{0, 1, -1, 127, 128, -112, -113, -129, 1 << 40, -(1 << 40), 1<<63 - 1, -1 << 63}
+	for _, v := range values {
+		var buf bytes.Buffer
+		writeVLong(&buf, v)
+		got, err := readVLong(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != v {
+			t.Errorf("Expecting vlong %d, but got %d", v, got)
+		}
+	}
+
+	d := DecodedToken{Owner: "alice", IssueDate: 1, MaxDate: 2, SequenceNumber: -5, Kind: "WEBHDFS delegation"}
+	back, err := d.Encode().Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Owner != d.Owner || back.SequenceNumber != d.SequenceNumber || back.Kind != d.Kind {
+		t.Errorf("Expecting %v, but got %v", d, back)
+	}
+}
+
+func Test_TokenDecodeInvalid(t *testing.T) {
+	for _, s := range []string{"", "token-1", "JQAIaG9y1afae2radfaerzcqdt14AfeE="} {
+		if _, err := (Token{UrlString: s}).Decode(); err == nil {
+			t.Errorf("Expecting error decoding %q", s)
+		}
+	}
+}
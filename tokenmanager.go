@@ -0,0 +1,239 @@
+package gowfs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default max lifetime of a delegation token, from hdfs-default.xml
+// (dfs.namenode.delegation.token.max-lifetime).
+const DefaultTokenMaxLifetime = 7 * 24 * time.Hour
+
+// Provides the current delegation token used to authenticate requests.
+type TokenSource interface {
+	Token() Token
+}
+
+// TokenManager owns a delegation token and keeps it valid.  The token is
+// renewed in the background before the expiration returned by
+// RENEWDELEGATIONTOKEN.  When the token reaches its max lifetime, a new
+// token is fetched and the old one is cancelled.  TokenManager implements
+// TokenSource, use FileSystem.WithTokenSource() to authenticate with it.
+type TokenManager struct {
+	Renewer     string        // renewer used to get tokens
+	MaxLifetime time.Duration // used when the token max date can't be decoded, default DefaultTokenMaxLifetime
+	RenewRatio  float64       // fraction of time to expiry to wait before renewal, default 0.8
+	RetryDelay  time.Duration // delay before retrying a failed renewal, default 1 minute
+	OnError     func(error)   // called when a renewal or fetch fails
+
+	fs       *FileSystem
+	mu       sync.RWMutex
+	token    Token
+	expires  time.Time // current expiration of token
+	maxDate  time.Time // expiration can not be renewed past maxDate
+	done     chan struct{}
+	stopped  chan struct{}
+	closeErr error
+	once     sync.Once
+
+	// clock, replaced by tests.
+	now   func() time.Time
+	timer func(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+// Creates a TokenManager that uses fs (which must authenticate without
+// delegation tokens, i.e. Kerberos) to get, renew and cancel tokens.
+func NewTokenManager(fs *FileSystem, renewer string) *TokenManager {
+	return &TokenManager{
+		Renewer:     renewer,
+		MaxLifetime: DefaultTokenMaxLifetime,
+		RenewRatio:  0.8,
+		RetryDelay:  time.Minute,
+		fs:          fs,
+	}
+}
+
+// Fetches the initial token and starts renewing it in the background.
+func (tm *TokenManager) Start() error {
+	if tm.done != nil {
+		return fmt.Errorf("TokenManager.Start() - already started.")
+	}
+	if tm.RenewRatio <= 0 || tm.RenewRatio >= 1 {
+		tm.RenewRatio = 0.8
+	}
+	if tm.MaxLifetime <= 0 {
+		tm.MaxLifetime = DefaultTokenMaxLifetime
+	}
+	if tm.RetryDelay <= 0 {
+		tm.RetryDelay = time.Minute
+	}
+	if tm.now == nil {
+		tm.now = time.Now
+	}
+	if tm.timer == nil {
+		tm.timer = func(d time.Duration) (<-chan time.Time, func() bool) {
+			t := time.NewTimer(d)
+			return t.C, t.Stop
+		}
+	}
+	if err := tm.fetch(); err != nil {
+		return err
+	}
+	tm.done = make(chan struct{})
+	tm.stopped = make(chan struct{})
+	go tm.run()
+	return nil
+}
+
+// Returns the current token.
+func (tm *TokenManager) Token() Token {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.token
+}
+
+// Returns the time the current token expires, unless renewed.
+func (tm *TokenManager) Expires() time.Time {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.expires
+}
+
+// Stops renewal and cancels the current token.
+func (tm *TokenManager) Close() error {
+	tm.once.Do(func() {
+		if tm.done != nil {
+			close(tm.done)
+			<-tm.stopped
+		}
+		token := tm.Token()
+		if token.UrlString == "" {
+			return
+		}
+		_, tm.closeErr = tm.fs.CancelDelegationToken(token.UrlString)
+	})
+	return tm.closeErr
+}
+
+func (tm *TokenManager) run() {
+	defer close(tm.stopped)
+	wait := tm.nextRenewal()
+	for {
+		fired, stop := tm.timer(wait)
+		select {
+		case <-tm.done:
+			stop()
+			return
+		case <-fired:
+		}
+
+		tm.mu.RLock()
+		replace := !tm.expires.Before(tm.maxDate) || tm.now().After(tm.expires)
+		tm.mu.RUnlock()
+
+		var err error
+		if replace {
+			err = tm.replace()
+		} else {
+			err = tm.renew()
+		}
+		if err != nil {
+			tm.report(err)
+			wait = tm.retryDelay()
+			continue
+		}
+		wait = tm.nextRenewal()
+	}
+}
+
+// Gets a new token and learns its expiration by renewing it.
+func (tm *TokenManager) fetch() error {
+	token, err := tm.fs.GetDelegationToken(tm.Renewer)
+	if err != nil {
+		return err
+	}
+	if token.UrlString == "" {
+		return fmt.Errorf("TokenManager - server returned an empty token.")
+	}
+	issued := tm.now()
+	exp, err := tm.fs.RenewDelegationToken(token.UrlString)
+	if err != nil {
+		return err
+	}
+
+	maxDate := issued.Add(tm.MaxLifetime)
+	if d, err := token.Decode(); err == nil && d.MaxDate > 0 {
+		maxDate = d.MaxTime()
+	}
+
+	tm.mu.Lock()
+	tm.token = token
+	tm.expires = msToTime(exp)
+	tm.maxDate = maxDate
+	tm.mu.Unlock()
+	return nil
+}
+
+// Replaces the current token with a new one, then cancels the old token.
+func (tm *TokenManager) replace() error {
+	old := tm.Token()
+	if err := tm.fetch(); err != nil {
+		return err
+	}
+	if _, err := tm.fs.CancelDelegationToken(old.UrlString); err != nil {
+		return fmt.Errorf("TokenManager - unable to cancel replaced token: %s", err.Error())
+	}
+	return nil
+}
+
+func (tm *TokenManager) renew() error {
+	exp, err := tm.fs.RenewDelegationToken(tm.Token().UrlString)
+	if err != nil {
+		return err
+	}
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if next := msToTime(exp); next.After(tm.expires) {
+		tm.expires = next
+	} else {
+		tm.maxDate = tm.expires // renewal no longer extends the token.
+	}
+	return nil
+}
+
+// Returns the delay until the next renewal.
+func (tm *TokenManager) nextRenewal() time.Duration {
+	tm.mu.RLock()
+	deadline := tm.expires
+	if tm.maxDate.Before(deadline) {
+		deadline = tm.maxDate
+	}
+	tm.mu.RUnlock()
+
+	wait := time.Duration(float64(deadline.Sub(tm.now())) * tm.RenewRatio)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// Returns the delay before retrying a failed renewal.  While the current
+// token is valid, retries are scheduled before it expires.
+func (tm *TokenManager) retryDelay() time.Duration {
+	if left := tm.Expires().Sub(tm.now()) / 2; left > 0 && left < tm.RetryDelay {
+		return left
+	}
+	return tm.RetryDelay
+}
+
+func (tm *TokenManager) report(err error) {
+	if tm.OnError != nil {
+		tm.OnError(err)
+	}
+}
+
+// Converts milliseconds since epoch (as returned by WebHDFS) to time.Time.
+func msToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -0,0 +1,176 @@
+package gowfs
+
+import "fmt"
+import "log"
+import "net/url"
+import "net/http"
+import "net/http/httptest"
+import "sync"
+import "time"
+
+import "testing"
+
+func Test_TokenManager(t *testing.T) {
+	clock := newFakeClock()
+	server := newMockTokenServer(clock.Now)
+	defer server.Close()
+	t.Logf("Test_TokenManager - Started httptest.Server on %v", server.URL)
+
+	url, _ := url.Parse(server.URL)
+	fs, _ := NewFileSystem(Configuration{Addr: url.Host})
+
+	tm := NewTokenManager(fs, "hdfsuser")
+	tm.MaxLifetime = 600 * time.Millisecond
+	tm.RenewRatio = 0.5
+	tm.OnError = func(err error) { t.Error(err) }
+	tm.now, tm.timer = clock.Now, clock.Timer
+	if err := tm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	first := tm.Token()
+	if first.UrlString != "token-1" {
+		t.Fatalf("Expecting token-1, but got %v", first.UrlString)
+	}
+
+	// renewed at half of the 200ms to expiry.
+	timer := clock.wait(t)
+	if timer.d != 100*time.Millisecond {
+		t.Errorf("Expecting renewal in 100ms, but got %v", timer.d)
+	}
+	for i := 0; i < 10 && tm.Token().UrlString == first.UrlString; i++ {
+		clock.fire(timer)
+		timer = clock.wait(t)
+	}
+	if tm.Token().UrlString == first.UrlString {
+		t.Error("TokenManager - token not replaced after max lifetime.")
+	}
+
+	// operations through the manager use its current token
+	tfs := fs.WithTokenSource(tm)
+	if _, err := tfs.GetFileStatus(Path{Name: "/test"}); err != nil {
+		t.Fatal(err)
+	}
+	if server.lastDelegation() != tm.Token().UrlString {
+		t.Errorf("Expecting delegation=%v, but got %v", tm.Token().UrlString, server.lastDelegation())
+	}
+
+	current := tm.Token()
+	if err := tm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !server.isCancelled(first.UrlString) || !server.isCancelled(current.UrlString) {
+		t.Error("TokenManager - tokens not cancelled.")
+	}
+	if server.renewals() < 3 {
+		t.Errorf("Expecting at least 3 renewals, but got %d", server.renewals())
+	}
+}
+
+// Clock advanced by firing the timers of a TokenManager.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers chan fakeTimer
+}
+
+type fakeTimer struct {
+	d time.Duration
+	c chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1400000000, 0), timers: make(chan fakeTimer, 16)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Timer(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := fakeTimer{d: d, c: make(chan time.Time, 1)}
+	c.timers <- timer
+	return timer.c, func() bool { return true }
+}
+
+// Returns the next timer, once the TokenManager is done with the
+// previous one.
+func (c *fakeClock) wait(t *testing.T) fakeTimer {
+	select {
+	case timer := <-c.timers:
+		return timer
+	case <-time.After(5 * time.Second):
+		t.Fatal("TokenManager - no renewal scheduled.")
+	}
+	return fakeTimer{}
+}
+
+// Advances the clock to timer and fires it.
+func (c *fakeClock) fire(timer fakeTimer) {
+	c.mu.Lock()
+	c.now = c.now.Add(timer.d)
+	now := c.now
+	c.mu.Unlock()
+	timer.c <- now
+}
+
+// *********************** Mock Servers ********************* //
+
+type mockTokenServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	issued     int
+	renewed    int
+	cancelled  map[string]bool
+	delegation string
+}
+
+func (s *mockTokenServer) renewals() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.renewed
+}
+
+func (s *mockTokenServer) isCancelled(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancelled[token]
+}
+
+func (s *mockTokenServer) lastDelegation() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.delegation
+}
+
+func newMockTokenServer(now func() time.Time) *mockTokenServer {
+	server := &mockTokenServer{cancelled: make(map[string]bool)}
+	handler := func(rsp http.ResponseWriter, req *http.Request) {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+
+		q := req.URL.Query()
+		switch q.Get("op") {
+		case OP_GETDELEGATIONTOKEN:
+			if q.Get("delegation") != "" {
+				log.Fatalf("Token operations must not use delegation param.")
+			}
+			server.issued++
+			fmt.Fprintf(rsp, `{"Token":{"urlString":"token-%d"}}`, server.issued)
+		case OP_RENEWDELEGATIONTOKEN:
+			server.renewed++
+			exp := now().Add(200*time.Millisecond).UnixNano() / int64(time.Millisecond)
+			fmt.Fprintf(rsp, `{"long":%d}`, exp)
+		case OP_CANCELDELEGATIONTOKEN:
+			server.cancelled[q.Get("token")] = true
+		case OP_GETFILESTATUS:
+			server.delegation = q.Get("delegation")
+			fmt.Fprint(rsp, fileStatusRsp)
+		default:
+			log.Fatalf("Unexpected op=%v", q.Get("op"))
+		}
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(handler))
+	return server
+}
@@ -10,9 +10,21 @@ type HdfsJsonData struct {
 	FileStatuses    FileStatuses
 	FileChecksum    FileChecksum
 	ContentSummary  ContentSummary
+	QuotaUsage      QuotaUsage
 	Token           Token
 	Tokens          Tokens
 	Long            int64
+	Location        string
+	Path            string
+	XAttrs          []XAttr
+	XAttrNames      string // JSON array encoded as a string, see ListXAttrs()
+	AclStatus       AclStatus
+	SnapshotDirs    []SnapshottableDirectoryStatus `json:"SnapshottableDirectoryList"`
+	SnapshotList    []SnapshotStatus
+	SnapshotDiff    SnapshotDiffReport        `json:"SnapshotDiffReport"`
+	DiffListing     SnapshotDiffReportListing `json:"SnapshotDiffReportListing"`
+	StoragePolicy   BlockStoragePolicy        `json:"BlockStoragePolicy"`
+	StoragePolicies BlockStoragePolicies      `json:"BlockStoragePolicies"`
 	RemoteException RemoteException
 }
 
@@ -51,6 +63,9 @@ type FileStatus struct {
 	PathSuffix       string
 	Permission       string
 	Replication      int64
+	StoragePolicy    int64  // id of the BlockStoragePolicy, 0 when unspecified
+	EcBit            bool   // true for erasure-coded files and directories
+	EcPolicy         string // name of the ErasureCodingPolicy when EcBit is set
 	Type             string
 }
 
@@ -73,7 +88,8 @@ type FileStatuses struct {
 //     "length"        : 24930,
 //     "quota"         : -1,
 //     "spaceConsumed" : 24930,
-//     "spaceQuota"    : -1
+//     "spaceQuota"    : -1,
+//     "typeQuota"     : { "ARCHIVE": { "consumed": 500, "quota": 10000 } }
 //   }
 // }
 type ContentSummary struct {
@@ -83,6 +99,36 @@ type ContentSummary struct {
 	Quota          int64
 	SpaceConsumed  int64
 	SpaceQuota     int64
+	TypeQuota      map[string]StorageTypeQuota // by storage type (i.e. StorageTypeArchive), only types with a quota
+}
+
+// Type for the quota of a storage type in ContentSummary and QuotaUsage.
+// Quota is -1 when not set.
+type StorageTypeQuota struct {
+	Consumed int64
+	Quota    int64
+}
+
+// Type for HDFS FileSystem quota usage (FileSystem.getQuotaUsage()).
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#QuotaUsage_JSON_Schema
+//
+// Example:
+// {
+//   "QuotaUsage":
+//   {
+//     "fileAndDirectoryCount": 1,
+//     "quota"                : 100,
+//     "spaceConsumed"        : 24930,
+//     "spaceQuota"           : 100000,
+//     "typeQuota"            : { "ARCHIVE": { "consumed": 500, "quota": 10000 } }
+//   }
+// }
+type QuotaUsage struct {
+	FileAndDirectoryCount int64
+	Quota                 int64
+	SpaceConsumed         int64
+	SpaceQuota            int64
+	TypeQuota             map[string]StorageTypeQuota
 }
 
 // 	Type for HDFS FileSystem.getFileChecksum()
@@ -124,6 +170,222 @@ type Tokens struct {
 	Token []Token
 }
 
+// Type for HDFS extended attributes (FileSystem.getXAttrs()).  Value is
+// encoded as requested: text in double quotes, hex prefixed with 0x or
+// base64 prefixed with 0s.  See XAttr.Bytes().
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#XAttrs_JSON_Schema
+//
+// Example:
+// {
+//   "XAttrs": [
+//     {
+//       "name" : "user.schema",
+//       "value": "\"v2\""
+//     }
+//   ]
+// }
+type XAttr struct {
+	Name  string
+	Value string
+}
+
+// Type for HDFS ACL status (FileSystem.getAclStatus()).  Entries only
+// holds the extended entries, the base entries are part of Permission.
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#ACL_Status_JSON_Schema
+//
+// Example:
+// {
+//   "AclStatus": {
+//     "entries": [
+//       "user:carla:rw-",
+//       "group::r-x"
+//     ],
+//     "group": "supergroup",
+//     "owner": "hadoop",
+//     "permission": "775",
+//     "stickyBit": false
+//   }
+// }
+type AclStatus struct {
+	Entries    []AclEntry
+	Group      string
+	Owner      string
+	Permission string
+	StickyBit  bool
+}
+
+// Type for a snapshottable directory (FileSystem.getSnapshottableDirListing()).
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#SnapshottableDirectoryList_JSON_Schema
+//
+// Example:
+// {
+//   "SnapshottableDirectoryList": [
+//     {
+//       "dirStatus": { "pathSuffix": "bar", "type": "DIRECTORY", ... },
+//       "parentFullPath": "/",
+//       "snapshotNumber": 2,
+//       "snapshotQuota": 65536
+//     }
+//   ]
+// }
+type SnapshottableDirectoryStatus struct {
+	DirStatus      FileStatus
+	ParentFullPath string
+	SnapshotNumber int64
+	SnapshotQuota  int64
+}
+
+// Type for a snapshot of a directory (FileSystem.getSnapshotListing()).
+// DirStatus.ModificationTime is the creation time of the snapshot.
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#SnapshotList_JSON_Schema
+//
+// Example:
+// {
+//   "SnapshotList": [
+//     {
+//       "dirStatus": { "modificationTime": 1646133271470, "type": "DIRECTORY", ... },
+//       "fullPath": "/bar/.snapshot/s1",
+//       "snapshotID": 1
+//     }
+//   ]
+// }
+type SnapshotStatus struct {
+	DirStatus  FileStatus
+	FullPath   string
+	SnapshotID int64
+}
+
+// Type for the difference between two snapshots (FileSystem.getSnapshotDiffReport()).
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#SnapshotDiffReport_JSON_Schema
+//
+// Example:
+// {
+//   "SnapshotDiffReport": {
+//     "diffList": [
+//       { "sourcePath": "bar", "type": "CREATE" },
+//       { "sourcePath": "foo", "targetPath": "foo2", "type": "RENAME" }
+//     ],
+//     "fromSnapshot": "s3",
+//     "snapshotRoot": "/foo",
+//     "toSnapshot": "s4"
+//   }
+// }
+type SnapshotDiffReport struct {
+	DiffList     []SnapshotDiffEntry
+	FromSnapshot string
+	SnapshotRoot string
+	ToSnapshot   string
+}
+
+// Type for a change between two snapshots.  Paths are relative to the
+// snapshot root, TargetPath is only set for renames.
+type SnapshotDiffEntry struct {
+	Type       SnapshotDiffType
+	SourcePath string
+	TargetPath string
+}
+
+// Type for a page of the difference between two snapshots
+// (DistributedFileSystem.getSnapshotDiffReportListing()).  The listing is
+// complete when LastPath is empty and LastIndex is -1.
+//
+// Example:
+// {
+//   "SnapshotDiffReportListing": {
+//     "createList": [ { "dirId": 16386, "fileId": 16388, "isReference": false, "sourcePath": "bar" } ],
+//     "deleteList": [],
+//     "modifyList": [ { "dirId": 16386, "fileId": 16386, "isReference": false, "sourcePath": "" } ],
+//     "isFromEarlier": true,
+//     "lastIndex": -1,
+//     "lastPath": ""
+//   }
+// }
+type SnapshotDiffReportListing struct {
+	CreateList    []SnapshotDiffListingEntry
+	DeleteList    []SnapshotDiffListingEntry
+	ModifyList    []SnapshotDiffListingEntry
+	IsFromEarlier bool
+	LastIndex     int64
+	LastPath      string
+}
+
+// Type for an entry of SnapshotDiffReportListing.  Modified entries hold
+// the path relative to the snapshot root; a modified directory has FileId
+// equal to DirId.  Created and deleted entries only hold the name of the
+// child of directory DirId.  Renamed paths are listed as a created and a
+// deleted reference to the same FileId, the deleted one holding the full
+// TargetPath.
+type SnapshotDiffListingEntry struct {
+	DirId       int64
+	FileId      int64
+	IsReference bool
+	SourcePath  string
+	TargetPath  string
+}
+
+// Type for HDFS block storage policies (FileSystem.getStoragePolicy()).
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#BlockStoragePolicy_JSON_Schema
+//
+// Example:
+// {
+//   "BlockStoragePolicy": {
+//     "copyOnCreateFile": false,
+//     "creationFallbacks": [],
+//     "id": 2,
+//     "name": "COLD",
+//     "replicationFallbacks": [],
+//     "storageTypes": ["ARCHIVE"]
+//   }
+// }
+type BlockStoragePolicy struct {
+	CopyOnCreateFile     bool
+	CreationFallbacks    []string
+	Id                   int64
+	Name                 string
+	ReplicationFallbacks []string
+	StorageTypes         []string
+}
+
+// Container type for BlockStoragePolicy (FileSystem.getAllStoragePolicies()).
+type BlockStoragePolicies struct {
+	BlockStoragePolicy []BlockStoragePolicy
+}
+
+// Type for HDFS erasure coding policies (FileSystem.getErasureCodingPolicy()).
+// See http://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#Get_EC_Policy
+//
+// Example:
+// {
+//   "cellSize": 1048576,
+//   "codecName": "rs",
+//   "id": 1,
+//   "name": "RS-6-3-1024k",
+//   "numDataUnits": 6,
+//   "numParityUnits": 3,
+//   "replicationPolicy": false,
+//   "schema": { "codecName": "rs", "extraOptions": {}, "numDataUnits": 6, "numParityUnits": 3 },
+//   "systemPolicy": true
+// }
+type ErasureCodingPolicy struct {
+	CellSize          int64
+	CodecName         string
+	Id                int64
+	Name              string
+	NumDataUnits      int64
+	NumParityUnits    int64
+	ReplicationPolicy bool
+	Schema            ErasureCodingSchema
+	SystemPolicy      bool
+}
+
+// Type for the codec of an ErasureCodingPolicy.
+type ErasureCodingSchema struct {
+	CodecName      string
+	ExtraOptions   map[string]string
+	NumDataUnits   int64
+	NumParityUnits int64
+}
+
 // Type for returning WebHDFS error/exceptions.
 // See http://hadoop.apache.org/docs/r2.2.0/hadoop-project-dist/hadoop-hdfs/WebHDFS.html#RemoteException_JSON_schema
 
@@ -146,3 +408,44 @@ type RemoteException struct {
 func (re RemoteException) Error() string {
 	return fmt.Sprintf("RemoteException: %v [%v]\n[%v]\n", re.Exception, re.JavaClassName, re.Message)
 }
+
+// Returned when a write to a datanode is interrupted (i.e. its context
+// is cancelled) after the transfer started.  The remote file may hold
+// part of the data and should be verified or removed by the caller.
+type IncompleteWriteError struct {
+	Op   string // OP_CREATE or OP_APPEND
+	Path Path
+	Err  error
+}
+
+func (e *IncompleteWriteError) Error() string {
+	return fmt.Sprintf("%v(%v) - write interrupted, remote file may be incomplete: %v", e.Op, e.Path.Name, e.Err)
+}
+
+func (e *IncompleteWriteError) Unwrap() error {
+	return e.Err
+}
+
+// Returned when an operation only applying to replicated files (i.e.
+// SetReplication()) is called on an erasure-coded file.
+type ErasureCodedFileError struct {
+	Op     string // i.e. OP_SETREPLICATION
+	Path   Path
+	Policy string // name of the ErasureCodingPolicy, if known
+	Err    error  // RemoteException returned by the namenode, if any
+}
+
+func (e *ErasureCodedFileError) Error() string {
+	msg := fmt.Sprintf("%v(%v) - not supported on erasure-coded files", e.Op, e.Path.Name)
+	if e.Policy != "" {
+		msg = fmt.Sprintf("%s (policy %s)", msg, e.Policy)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *ErasureCodedFileError) Unwrap() error {
+	return e.Err
+}